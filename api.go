@@ -4,6 +4,7 @@ package gorqlite
 
 import (
 	"context"
+	"io"
 	"net/http"
 	"net/url"
 )
@@ -15,6 +16,22 @@ type APIClient interface {
 	PostWithContext(ctx context.Context, path string, query url.Values, body []byte) (*http.Response, error)
 }
 
+// StreamAPIClient is optionally implemented by an APIClient that can stream
+// request bodies, which is required by Gorqlite.Load.
+type StreamAPIClient interface {
+	// PostStreamWithContext sends a POST request with the body read from
+	// body rather than buffered in memory. If contentType is not empty it is
+	// set as the Content-Type header.
+	PostStreamWithContext(ctx context.Context, path string, query url.Values, contentType string, body io.Reader) (*http.Response, error)
+}
+
+// DeleteAPIClient is optionally implemented by an APIClient that can send
+// DELETE requests, which is required by Gorqlite.RemoveNode.
+type DeleteAPIClient interface {
+	// DeleteWithContext sends a DELETE request with the given body.
+	DeleteWithContext(ctx context.Context, path string, query url.Values, body []byte) (*http.Response, error)
+}
+
 func isStatusOK(statusCode int) bool {
 	return statusCode >= 200 && statusCode <= 299
 }
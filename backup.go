@@ -0,0 +1,113 @@
+package gorqlite
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/url"
+)
+
+// Backup writes a backup of the database to w. The backup is streamed to w
+// as it is received rather than buffered in memory, so it is safe to back up
+// large databases.
+// See https://github.com/rqlite/rqlite/blob/cc74ab0af7c128582b7f0fd380033d43e642a121/DOC/BACKUPS.md.
+//
+// By default the backup is a SQLite database file taken by the leader. Use
+// WithBackupFormat(BackupFormatSQL) for a SQL text dump instead.
+//
+// Unless WithBackupLeaderOnly(false) is set, the backup is sent to the
+// leader even if leader redirect is disabled. If there is no leader the
+// error wraps a *NotLeaderError. If the backup fails after writing has
+// started, w may contain a partial backup.
+func (g *Gorqlite) Backup(ctx context.Context, w io.Writer, opts ...BackupOption) error {
+	conf := defaultBackupConfig()
+	for _, opt := range opts {
+		opt(conf)
+	}
+
+	query := url.Values{}
+	switch conf.Format {
+	case BackupFormatBinary:
+	case BackupFormatSQL:
+		query.Add("fmt", "sql")
+	default:
+		return newError("backup failed: invalid format: %s", conf.Format)
+	}
+	if conf.Compress {
+		query.Add("compress", "")
+	}
+	if !conf.LeaderOnly {
+		query.Add("noleader", "")
+	}
+
+	resp, err := g.apiClient.GetWithContext(ctx, "/db/backup", query)
+	if err != nil {
+		return wrapError(notLeaderError(err), "backup failed: request failed")
+	}
+	defer resp.Body.Close()
+
+	if !isStatusOK(resp.StatusCode) {
+		return wrapError(notLeaderError(newStatusError("", resp)), "backup failed")
+	}
+
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return wrapError(err, "backup failed: failed to write backup")
+	}
+	return nil
+}
+
+// Load loads the database from r, which is either a SQLite database file
+// (BackupFormatBinary) or a SQL text dump (BackupFormatSQL), such as a backup
+// written by Backup. The data is streamed from r rather than buffered in
+// memory.
+// See https://github.com/rqlite/rqlite/blob/cc74ab0af7c128582b7f0fd380033d43e642a121/DOC/BACKUPS.md#restoring-from-sqlite.
+//
+// The load is sent directly to the leader rather than forwarded by another
+// node, using the cached leader if known. Since r can only be read once, if
+// the node is not the leader the load fails with an error wrapping a
+// *NotLeaderError, unless r implements io.Seeker (such as *os.File) in which
+// case r is rewound to its offset when Load was called and sent to the
+// leader.
+//
+// A custom API client (see OpenWithClient) must implement StreamAPIClient to
+// load the database.
+func (g *Gorqlite) Load(ctx context.Context, r io.Reader, format BackupFormat) error {
+	var contentType string
+	switch format {
+	case BackupFormatBinary:
+		contentType = "application/octet-stream"
+	case BackupFormatSQL:
+		contentType = "text/plain"
+	default:
+		return newError("load failed: invalid format: %s", format)
+	}
+
+	apiClient, ok := g.apiClient.(StreamAPIClient)
+	if !ok {
+		return newError("load failed: api client does not support streaming requests")
+	}
+
+	resp, err := apiClient.PostStreamWithContext(ctx, "/db/load", url.Values{}, contentType, r)
+	if err != nil {
+		return wrapError(notLeaderError(err), "load failed: request failed")
+	}
+	defer resp.Body.Close()
+
+	if !isStatusOK(resp.StatusCode) {
+		return wrapError(notLeaderError(newStatusError("", resp)), "load failed")
+	}
+
+	// Loading a SQL dump returns the result of each statement.
+	var loadResp executeResponse
+	if err := json.NewDecoder(resp.Body).Decode(&loadResp); err != nil && !errors.Is(err, io.EOF) {
+		return wrapError(err, "load failed: invalid response")
+	}
+	if loadResp.Error != "" {
+		return newError("load failed: %s", loadResp.Error)
+	}
+	if results := ExecuteResults(loadResp.Results); results.HasError() {
+		return newError("load failed: %s", results.GetFirstError())
+	}
+	return nil
+}
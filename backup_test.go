@@ -0,0 +1,321 @@
+package gorqlite_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/dunstall/gorqlite"
+	mock_gorqlite "github.com/dunstall/gorqlite/mocks/api"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGorqlite_Backup(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	apiClient := mock_gorqlite.NewMockAPIClient(ctrl)
+	apiClient.EXPECT().GetWithContext(
+		gomock.Any(), "/db/backup", url.Values{},
+	).Return(httpResponse(http.StatusOK, strings.NewReader("SQLite format 3")), nil)
+
+	conn := gorqlite.OpenWithClient(apiClient)
+	var buf bytes.Buffer
+	require.Nil(t, conn.Backup(context.Background(), &buf))
+	require.Equal(t, "SQLite format 3", buf.String())
+}
+
+func TestGorqlite_BackupWithOptions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	query := url.Values{}
+	query.Add("fmt", "sql")
+	query.Add("compress", "")
+	query.Add("noleader", "")
+	apiClient := mock_gorqlite.NewMockAPIClient(ctrl)
+	apiClient.EXPECT().GetWithContext(
+		gomock.Any(), "/db/backup", query,
+	).Return(httpResponse(http.StatusOK, strings.NewReader("dump")), nil)
+
+	conn := gorqlite.OpenWithClient(apiClient)
+	var buf bytes.Buffer
+	require.Nil(t, conn.Backup(
+		context.Background(),
+		&buf,
+		gorqlite.WithBackupFormat(gorqlite.BackupFormatSQL),
+		gorqlite.WithBackupCompress(true),
+		gorqlite.WithBackupLeaderOnly(false),
+	))
+	require.Equal(t, "dump", buf.String())
+}
+
+func TestGorqlite_BackupInvalidFormat(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	apiClient := mock_gorqlite.NewMockAPIClient(ctrl)
+
+	conn := gorqlite.OpenWithClient(apiClient)
+	err := conn.Backup(
+		context.Background(), ioutil.Discard, gorqlite.WithBackupFormat("csv"),
+	)
+	require.EqualError(t, err, "backup failed: invalid format: csv")
+}
+
+// Tests the backup is written as it is read, rather than reading the whole
+// backup into memory.
+func TestGorqlite_BackupStreaming(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	size := int64(64 * 1024 * 1024)
+	body := &backupReader{remaining: size, read: 0}
+	apiClient := mock_gorqlite.NewMockAPIClient(ctrl)
+	apiClient.EXPECT().GetWithContext(
+		gomock.Any(), "/db/backup", url.Values{},
+	).Return(httpResponse(http.StatusOK, body), nil)
+
+	conn := gorqlite.OpenWithClient(apiClient)
+	w := &backupWriter{body: body, written: 0, maxBuffered: 0}
+	require.Nil(t, conn.Backup(context.Background(), w))
+	require.Equal(t, size, w.written)
+	// Only a small buffer should be read ahead of what was written.
+	require.True(t, w.maxBuffered <= 1024*1024, "buffered %d bytes", w.maxBuffered)
+}
+
+func TestGorqlite_BackupNotLeader(t *testing.T) {
+	tests := []struct {
+		name string
+		resp *http.Response
+	}{
+		{
+			"redirect",
+			httpResponse(http.StatusMovedPermanently, strings.NewReader("")),
+		},
+		{
+			"leader not found",
+			httpResponse(http.StatusServiceUnavailable, strings.NewReader("leader not found")),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			apiClient := mock_gorqlite.NewMockAPIClient(ctrl)
+			apiClient.EXPECT().GetWithContext(
+				gomock.Any(), "/db/backup", url.Values{},
+			).Return(tt.resp, nil)
+
+			conn := gorqlite.OpenWithClient(apiClient)
+			err := conn.Backup(context.Background(), ioutil.Discard)
+			var notLeaderErr *gorqlite.NotLeaderError
+			require.True(t, errors.As(err, &notLeaderErr))
+			var statusErr *gorqlite.StatusError
+			require.True(t, errors.As(err, &statusErr))
+			require.Equal(t, tt.resp.StatusCode, statusErr.StatusCode)
+		})
+	}
+}
+
+func TestGorqlite_BackupBadStatus(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	apiClient := mock_gorqlite.NewMockAPIClient(ctrl)
+	apiClient.EXPECT().GetWithContext(
+		gomock.Any(), "/db/backup", url.Values{},
+	).Return(httpResponse(http.StatusInternalServerError, strings.NewReader("")), nil)
+
+	conn := gorqlite.OpenWithClient(apiClient)
+	err := conn.Backup(context.Background(), ioutil.Discard)
+	require.Error(t, err)
+	var notLeaderErr *gorqlite.NotLeaderError
+	require.False(t, errors.As(err, &notLeaderErr))
+}
+
+func TestGorqlite_BackupNetworkError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	apiClient := mock_gorqlite.NewMockAPIClient(ctrl)
+	apiClient.EXPECT().GetWithContext(
+		gomock.Any(), "/db/backup", url.Values{},
+	).Return(nil, fmt.Errorf("network err"))
+
+	conn := gorqlite.OpenWithClient(apiClient)
+	err := conn.Backup(context.Background(), ioutil.Discard)
+	require.Error(t, err)
+}
+
+// backupReader generates a backup of the given size as it is read.
+type backupReader struct {
+	remaining int64
+	read      int64
+}
+
+func (r *backupReader) Read(p []byte) (int, error) {
+	if r.remaining == 0 {
+		return 0, io.EOF
+	}
+	n := len(p)
+	if int64(n) > r.remaining {
+		n = int(r.remaining)
+	}
+	for i := 0; i < n; i++ {
+		p[i] = byte(i)
+	}
+	r.remaining -= int64(n)
+	r.read += int64(n)
+	return n, nil
+}
+
+// backupWriter records the maximum number of bytes read from body but not
+// yet written.
+type backupWriter struct {
+	body        *backupReader
+	written     int64
+	maxBuffered int64
+}
+
+func (w *backupWriter) Write(p []byte) (int, error) {
+	w.written += int64(len(p))
+	if buffered := w.body.read - w.written; buffered > w.maxBuffered {
+		w.maxBuffered = buffered
+	}
+	return len(p), nil
+}
+
+func TestGorqlite_Load(t *testing.T) {
+	tests := []struct {
+		name        string
+		format      gorqlite.BackupFormat
+		contentType string
+		respBody    string
+	}{
+		{"binary", gorqlite.BackupFormatBinary, "application/octet-stream", `{"results": []}`},
+		{"sql", gorqlite.BackupFormatSQL, "text/plain", `{"results": [{}, {"last_insert_id": 1, "rows_affected": 1}]}`},
+		{"empty response", gorqlite.BackupFormatBinary, "application/octet-stream", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			body := strings.NewReader("data")
+			apiClient := mock_gorqlite.NewMockStreamAPIClient(ctrl)
+			apiClient.EXPECT().PostStreamWithContext(
+				gomock.Any(), "/db/load", url.Values{}, tt.contentType, body,
+			).Return(httpResponse(http.StatusOK, strings.NewReader(tt.respBody)), nil)
+
+			conn := gorqlite.OpenWithClient(newStreamAPIClient(ctrl, apiClient))
+			require.Nil(t, conn.Load(context.Background(), body, tt.format))
+		})
+	}
+}
+
+func TestGorqlite_LoadInvalidFormat(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	apiClient := mock_gorqlite.NewMockAPIClient(ctrl)
+
+	conn := gorqlite.OpenWithClient(apiClient)
+	err := conn.Load(context.Background(), strings.NewReader(""), "csv")
+	require.EqualError(t, err, "load failed: invalid format: csv")
+}
+
+func TestGorqlite_LoadErrorResults(t *testing.T) {
+	tests := []struct {
+		name     string
+		respBody string
+		err      string
+	}{
+		{"response error", `{"error": "invalid load"}`, "load failed: invalid load"},
+		{
+			"statement error",
+			`{"results": [{}, {"error": "no such table: foo"}]}`,
+			"load failed: no such table: foo",
+		},
+		{"invalid response", `{"results": `, "load failed: invalid response: unexpected EOF"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			apiClient := mock_gorqlite.NewMockStreamAPIClient(ctrl)
+			apiClient.EXPECT().PostStreamWithContext(
+				gomock.Any(), "/db/load", url.Values{}, "text/plain", gomock.Any(),
+			).Return(httpResponse(http.StatusOK, strings.NewReader(tt.respBody)), nil)
+
+			conn := gorqlite.OpenWithClient(newStreamAPIClient(ctrl, apiClient))
+			err := conn.Load(context.Background(), strings.NewReader("CREATE ..."), gorqlite.BackupFormatSQL)
+			require.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+func TestGorqlite_LoadNotLeader(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	apiClient := mock_gorqlite.NewMockStreamAPIClient(ctrl)
+	apiClient.EXPECT().PostStreamWithContext(
+		gomock.Any(), "/db/load", url.Values{}, "application/octet-stream", gomock.Any(),
+	).Return(httpResponse(http.StatusServiceUnavailable, strings.NewReader("not leader")), nil)
+
+	conn := gorqlite.OpenWithClient(newStreamAPIClient(ctrl, apiClient))
+	err := conn.Load(context.Background(), strings.NewReader(""), gorqlite.BackupFormatBinary)
+	var notLeaderErr *gorqlite.NotLeaderError
+	require.True(t, errors.As(err, &notLeaderErr))
+}
+
+func TestGorqlite_LoadUnsupportedClient(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// The client doesn't implement StreamAPIClient.
+	apiClient := mock_gorqlite.NewMockAPIClient(ctrl)
+
+	conn := gorqlite.OpenWithClient(apiClient)
+	err := conn.Load(context.Background(), strings.NewReader(""), gorqlite.BackupFormatBinary)
+	require.EqualError(t, err, "load failed: api client does not support streaming requests")
+}
+
+func TestGorqlite_LoadNetworkError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	apiClient := mock_gorqlite.NewMockStreamAPIClient(ctrl)
+	apiClient.EXPECT().PostStreamWithContext(
+		gomock.Any(), "/db/load", url.Values{}, "application/octet-stream", gomock.Any(),
+	).Return(nil, fmt.Errorf("network err"))
+
+	conn := gorqlite.OpenWithClient(newStreamAPIClient(ctrl, apiClient))
+	err := conn.Load(context.Background(), strings.NewReader(""), gorqlite.BackupFormatBinary)
+	require.Error(t, err)
+}
+
+// streamAPIClient is a mock API client that also implements
+// gorqlite.StreamAPIClient.
+type streamAPIClient struct {
+	*mock_gorqlite.MockAPIClient
+	*mock_gorqlite.MockStreamAPIClient
+}
+
+func newStreamAPIClient(ctrl *gomock.Controller, streamClient *mock_gorqlite.MockStreamAPIClient) *streamAPIClient {
+	return &streamAPIClient{
+		MockAPIClient:       mock_gorqlite.NewMockAPIClient(ctrl),
+		MockStreamAPIClient: streamClient,
+	}
+}
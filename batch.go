@@ -0,0 +1,161 @@
+package gorqlite
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// BatchWriter writes a large number of statements by splitting them into
+// chunks, each written with a separate execute request. Chunks are bounded
+// by both the number of statements and the size of the request body (see
+// WithBatchMaxStatements and WithBatchMaxBytes).
+//
+// If rqlite rejects a chunk as too large (with status 413), the chunk is
+// split in half and each half written separately.
+//
+// Statements are buffered with Add until written with Flush:
+//
+//	batch := conn.NewBatchWriter()
+//	for _, name := range names {
+//	  batch.Add(gorqlite.NewStatement("INSERT INTO foo(name) VALUES(?)", name))
+//	}
+//	results, err := batch.Flush()
+//
+// Since each chunk is a separate request, WithTransaction only applies to
+// the statements within each chunk rather than the whole batch. Similarly
+// when using WithQueue no results are returned, and the sequence number is
+// that of the last chunk.
+//
+// BatchWriter is not safe for concurrent use.
+type BatchWriter struct {
+	g     *Gorqlite
+	conf  *batchConfig
+	stmts []Statement
+}
+
+// NewBatchWriter returns a BatchWriter that writes to this client.
+func (g *Gorqlite) NewBatchWriter(opts ...BatchOption) *BatchWriter {
+	conf := defaultBatchConfig()
+	for _, opt := range opts {
+		opt(conf)
+	}
+	return &BatchWriter{
+		g:     g,
+		conf:  conf,
+		stmts: nil,
+	}
+}
+
+// Add buffers the given statements to be written on Flush.
+func (b *BatchWriter) Add(stmts ...Statement) {
+	b.stmts = append(b.stmts, stmts...)
+}
+
+// Len returns the number of buffered statements.
+func (b *BatchWriter) Len() int {
+	return len(b.stmts)
+}
+
+// Flush writes the buffered statements and returns the execute result of
+// each statement, in the order the statements were added.
+//
+// The buffer is cleared even if the flush fails. On failure the returned
+// results contain the results of the statements written before the failure,
+// so the statements from len(results) onwards were not written.
+func (b *BatchWriter) Flush(opts ...ExecuteOption) (ExecuteResults, error) {
+	return b.FlushWithContext(context.Background(), opts...)
+}
+
+func (b *BatchWriter) FlushWithContext(ctx context.Context, opts ...ExecuteOption) (ExecuteResults, error) {
+	stmts := b.stmts
+	b.stmts = nil
+
+	chunks, err := b.chunks(stmts)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make(ExecuteResults, 0, len(stmts))
+	for _, chunk := range chunks {
+		results, err = b.write(ctx, chunk, results, opts)
+		if err != nil {
+			return results, err
+		}
+	}
+	return results, nil
+}
+
+// chunks splits the statements into chunks bounded by the maximum number
+// of statements and request body size.
+func (b *BatchWriter) chunks(stmts []Statement) ([][]Statement, error) {
+	var chunks [][]Statement
+	start := 0
+	// The size of the request body, which is a JSON array of statements.
+	size := 2
+	for i, stmt := range stmts {
+		encoded, err := json.Marshal(stmt)
+		if err != nil {
+			return nil, wrapError(err, "execute failed: failed to marshal query")
+		}
+		stmtSize := len(encoded)
+		if i > start {
+			// Separating comma.
+			stmtSize++
+		}
+
+		full := (b.conf.MaxStatements > 0 && i-start >= b.conf.MaxStatements) ||
+			(b.conf.MaxBytes > 0 && size+stmtSize > b.conf.MaxBytes)
+		if i > start && full {
+			chunks = append(chunks, stmts[start:i])
+			start = i
+			size = 2
+			stmtSize = len(encoded)
+		}
+		size += stmtSize
+	}
+	if start < len(stmts) {
+		chunks = append(chunks, stmts[start:])
+	}
+	return chunks, nil
+}
+
+// write writes the chunk and appends its results to results. If the chunk
+// is too large it is split in half.
+func (b *BatchWriter) write(ctx context.Context, chunk []Statement, results ExecuteResults, opts []ExecuteOption) (ExecuteResults, error) {
+	chunkResults, err := b.g.ExecuteStatementsWithContext(ctx, chunk, opts...)
+	if err != nil {
+		if !isTooLarge(err) || len(chunk) == 1 {
+			return results, err
+		}
+
+		mid := len(chunk) / 2
+		results, err = b.write(ctx, chunk[:mid], results, opts)
+		if err != nil {
+			return results, err
+		}
+		return b.write(ctx, chunk[mid:], results, opts)
+	}
+
+	// Queued writes don't return any results.
+	if newExecuteConfig(opts...).Queue != nil {
+		return results, nil
+	}
+
+	// If a statement fails within a transaction, rqlite doesn't return
+	// results for the following statements so add results for those
+	// statements to keep the results in input order.
+	for len(chunkResults) < len(chunk) {
+		chunkResults = append(chunkResults, ExecuteResult{
+			Error: "statement not executed",
+		})
+	}
+	return append(results, chunkResults...), nil
+}
+
+func isTooLarge(err error) bool {
+	var statusErr *StatusError
+	return errors.As(err, &statusErr) &&
+		statusErr.StatusCode == http.StatusRequestEntityTooLarge
+}
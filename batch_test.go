@@ -0,0 +1,258 @@
+package gorqlite_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/dunstall/gorqlite"
+	mock_gorqlite "github.com/dunstall/gorqlite/mocks/api"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchWriter_FlushMaxStatements(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	apiClient := mock_gorqlite.NewMockAPIClient(ctrl)
+	gomock.InOrder(
+		apiClient.EXPECT().PostWithContext(
+			gomock.Any(), "/db/execute", url.Values{}, []byte(`["INSERT 1","INSERT 2"]`),
+		).Return(executeResponse(1, 2), nil),
+		apiClient.EXPECT().PostWithContext(
+			gomock.Any(), "/db/execute", url.Values{}, []byte(`["INSERT 3","INSERT 4"]`),
+		).Return(executeResponse(3, 4), nil),
+		apiClient.EXPECT().PostWithContext(
+			gomock.Any(), "/db/execute", url.Values{}, []byte(`["INSERT 5"]`),
+		).Return(executeResponse(5), nil),
+	)
+
+	conn := gorqlite.OpenWithClient(apiClient)
+	batch := conn.NewBatchWriter(gorqlite.WithBatchMaxStatements(2))
+	for i := 1; i <= 5; i++ {
+		batch.Add(gorqlite.NewStatement(fmt.Sprintf("INSERT %d", i)))
+	}
+	require.Equal(t, 5, batch.Len())
+
+	results, err := batch.Flush()
+	require.Nil(t, err)
+	require.Equal(t, executeResults(1, 2, 3, 4, 5), results)
+	require.Equal(t, 0, batch.Len())
+}
+
+func TestBatchWriter_FlushNoMaximum(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// With no maximum all statements are written in a single request.
+	apiClient := mock_gorqlite.NewMockAPIClient(ctrl)
+	apiClient.EXPECT().PostWithContext(
+		gomock.Any(), "/db/execute", url.Values{},
+		[]byte(`["INSERT 1","INSERT 2","INSERT 3","INSERT 4","INSERT 5"]`),
+	).Return(executeResponse(1, 2, 3, 4, 5), nil)
+
+	conn := gorqlite.OpenWithClient(apiClient)
+	batch := conn.NewBatchWriter(
+		gorqlite.WithBatchMaxStatements(0),
+		gorqlite.WithBatchMaxBytes(0),
+	)
+	for i := 1; i <= 5; i++ {
+		batch.Add(gorqlite.NewStatement(fmt.Sprintf("INSERT %d", i)))
+	}
+
+	results, err := batch.Flush()
+	require.Nil(t, err)
+	require.Equal(t, executeResults(1, 2, 3, 4, 5), results)
+}
+
+func TestBatchWriter_FlushMaxBytes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	apiClient := mock_gorqlite.NewMockAPIClient(ctrl)
+	gomock.InOrder(
+		// Each statement is 10 bytes, so with the brackets and separating
+		// comma only two statements fit in 23 bytes.
+		apiClient.EXPECT().PostWithContext(
+			gomock.Any(), "/db/execute", url.Values{}, []byte(`["INSERT 1","INSERT 2"]`),
+		).Return(executeResponse(1, 2), nil),
+		apiClient.EXPECT().PostWithContext(
+			gomock.Any(), "/db/execute", url.Values{}, []byte(`["INSERT 3"]`),
+		).Return(executeResponse(3), nil),
+	)
+
+	conn := gorqlite.OpenWithClient(apiClient)
+	batch := conn.NewBatchWriter(gorqlite.WithBatchMaxBytes(23))
+	batch.Add(
+		gorqlite.NewStatement("INSERT 1"),
+		gorqlite.NewStatement("INSERT 2"),
+		gorqlite.NewStatement("INSERT 3"),
+	)
+
+	results, err := batch.Flush()
+	require.Nil(t, err)
+	require.Equal(t, executeResults(1, 2, 3), results)
+}
+
+func TestBatchWriter_FlushSplitsTooLarge(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	apiClient := mock_gorqlite.NewMockAPIClient(ctrl)
+	gomock.InOrder(
+		apiClient.EXPECT().PostWithContext(
+			gomock.Any(), "/db/execute", url.Values{}, []byte(`["INSERT 1","INSERT 2","INSERT 3"]`),
+		).Return(httpResponse(http.StatusRequestEntityTooLarge, strings.NewReader("")), nil),
+		apiClient.EXPECT().PostWithContext(
+			gomock.Any(), "/db/execute", url.Values{}, []byte(`["INSERT 1"]`),
+		).Return(executeResponse(1), nil),
+		apiClient.EXPECT().PostWithContext(
+			gomock.Any(), "/db/execute", url.Values{}, []byte(`["INSERT 2","INSERT 3"]`),
+		).Return(httpResponse(http.StatusRequestEntityTooLarge, strings.NewReader("")), nil),
+		apiClient.EXPECT().PostWithContext(
+			gomock.Any(), "/db/execute", url.Values{}, []byte(`["INSERT 2"]`),
+		).Return(executeResponse(2), nil),
+		apiClient.EXPECT().PostWithContext(
+			gomock.Any(), "/db/execute", url.Values{}, []byte(`["INSERT 3"]`),
+		).Return(executeResponse(3), nil),
+	)
+
+	conn := gorqlite.OpenWithClient(apiClient)
+	batch := conn.NewBatchWriter()
+	batch.Add(
+		gorqlite.NewStatement("INSERT 1"),
+		gorqlite.NewStatement("INSERT 2"),
+		gorqlite.NewStatement("INSERT 3"),
+	)
+
+	results, err := batch.Flush()
+	require.Nil(t, err)
+	require.Equal(t, executeResults(1, 2, 3), results)
+}
+
+func TestBatchWriter_FlushStatementTooLarge(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	apiClient := mock_gorqlite.NewMockAPIClient(ctrl)
+	gomock.InOrder(
+		apiClient.EXPECT().PostWithContext(
+			gomock.Any(), "/db/execute", url.Values{}, []byte(`["INSERT 1","INSERT 2"]`),
+		).Return(httpResponse(http.StatusRequestEntityTooLarge, strings.NewReader("")), nil),
+		apiClient.EXPECT().PostWithContext(
+			gomock.Any(), "/db/execute", url.Values{}, []byte(`["INSERT 1"]`),
+		).Return(executeResponse(1), nil),
+		apiClient.EXPECT().PostWithContext(
+			gomock.Any(), "/db/execute", url.Values{}, []byte(`["INSERT 2"]`),
+		).Return(httpResponse(http.StatusRequestEntityTooLarge, strings.NewReader("")), nil),
+	)
+
+	conn := gorqlite.OpenWithClient(apiClient)
+	batch := conn.NewBatchWriter()
+	batch.Add(
+		gorqlite.NewStatement("INSERT 1"),
+		gorqlite.NewStatement("INSERT 2"),
+	)
+
+	results, err := batch.Flush()
+	var statusErr *gorqlite.StatusError
+	require.True(t, errors.As(err, &statusErr))
+	require.Equal(t, http.StatusRequestEntityTooLarge, statusErr.StatusCode)
+	// Only the statements before the failure were written.
+	require.Equal(t, executeResults(1), results)
+	require.Equal(t, 0, batch.Len())
+}
+
+func TestBatchWriter_FlushTransactionError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	body := `{"results": [{"last_insert_id": 1, "rows_affected": 1}, {"error": "no such table: bar"}]}`
+	query := url.Values{}
+	query.Add("transaction", "")
+	apiClient := mock_gorqlite.NewMockAPIClient(ctrl)
+	apiClient.EXPECT().PostWithContext(
+		gomock.Any(), "/db/execute", query, []byte(`["INSERT 1","INSERT 2","INSERT 3"]`),
+	).Return(httpResponse(http.StatusOK, strings.NewReader(body)), nil)
+
+	conn := gorqlite.OpenWithClient(apiClient)
+	batch := conn.NewBatchWriter()
+	batch.Add(
+		gorqlite.NewStatement("INSERT 1"),
+		gorqlite.NewStatement("INSERT 2"),
+		gorqlite.NewStatement("INSERT 3"),
+	)
+
+	results, err := batch.Flush(gorqlite.WithTransaction(true))
+	require.Nil(t, err)
+	require.Equal(t, gorqlite.ExecuteResults{
+		{LastInsertId: 1, RowsAffected: 1},
+		{Error: "no such table: bar"},
+		{Error: "statement not executed"},
+	}, results)
+}
+
+func TestBatchWriter_FlushQueued(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	query := url.Values{}
+	query.Add("queue", "")
+	apiClient := mock_gorqlite.NewMockAPIClient(ctrl)
+	gomock.InOrder(
+		apiClient.EXPECT().PostWithContext(
+			gomock.Any(), "/db/execute", query, []byte(`["INSERT 1"]`),
+		).Return(httpResponse(http.StatusOK, strings.NewReader(`{"results": [], "sequence_number": 1}`)), nil),
+		apiClient.EXPECT().PostWithContext(
+			gomock.Any(), "/db/execute", query, []byte(`["INSERT 2"]`),
+		).Return(httpResponse(http.StatusOK, strings.NewReader(`{"results": [], "sequence_number": 2}`)), nil),
+	)
+
+	conn := gorqlite.OpenWithClient(apiClient)
+	batch := conn.NewBatchWriter(gorqlite.WithBatchMaxStatements(1))
+	batch.Add(
+		gorqlite.NewStatement("INSERT 1"),
+		gorqlite.NewStatement("INSERT 2"),
+	)
+
+	var queued gorqlite.QueuedWrite
+	results, err := batch.Flush(gorqlite.WithQueue(&queued))
+	require.Nil(t, err)
+	require.Equal(t, 0, len(results))
+	require.Equal(t, int64(2), queued.SequenceNumber)
+}
+
+func TestBatchWriter_FlushEmpty(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	apiClient := mock_gorqlite.NewMockAPIClient(ctrl)
+
+	conn := gorqlite.OpenWithClient(apiClient)
+	results, err := conn.NewBatchWriter().Flush()
+	require.Nil(t, err)
+	require.Equal(t, 0, len(results))
+}
+
+// executeResponse returns a response with an execute result for each of the
+// given insert IDs.
+func executeResponse(ids ...int64) *http.Response {
+	results := make([]string, 0, len(ids))
+	for _, id := range ids {
+		results = append(results, fmt.Sprintf(`{"last_insert_id": %d, "rows_affected": 1}`, id))
+	}
+	body := fmt.Sprintf(`{"results": [%s]}`, strings.Join(results, ","))
+	return httpResponse(http.StatusOK, strings.NewReader(body))
+}
+
+func executeResults(ids ...int64) gorqlite.ExecuteResults {
+	results := make(gorqlite.ExecuteResults, 0, len(ids))
+	for _, id := range ids {
+		results = append(results, gorqlite.ExecuteResult{LastInsertId: id, RowsAffected: 1})
+	}
+	return results
+}
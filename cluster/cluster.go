@@ -45,10 +45,17 @@ func OpenCluster(numNodes uint32) (*Cluster, error) {
 	return cluster, nil
 }
 
-// WaitForHealthy waits for all nodes to return a status with a consistent
-// view of the clusters nodes and leader.
+// WaitForHealthy waits for all nodes to be ready and return a status with a
+// consistent view of the clusters nodes and leader.
 func (c *Cluster) WaitForHealthy(ctx context.Context) bool {
+	conn := gorqlite.Open(c.Addrs())
+	if err := conn.WaitUntilReady(ctx); err != nil {
+		log.Debugf("failed to wait for nodes to be ready: %s", err)
+		return false
+	}
+
 	ticker := time.NewTicker(250 * time.Millisecond)
+	defer ticker.Stop()
 	for {
 		select {
 		case <-ctx.Done():
@@ -89,11 +96,44 @@ func (c *Cluster) AddNode(node *Node) {
 	c.nodes[node.ID()] = node
 }
 
-func (c *Cluster) RemoveNode(id uint32) {
-	if node, ok := c.nodes[id]; ok {
-		node.Close()
-		delete(c.nodes, id)
+// RemoveNode removes the node from the cluster via the remaining nodes, then
+// stops the node.
+func (c *Cluster) RemoveNode(ctx context.Context, id uint32) error {
+	node, ok := c.nodes[id]
+	if !ok {
+		return newError("unknown node: %d", id)
+	}
+
+	addrs := []string{}
+	for nodeID, addr := range c.NodeAddrs() {
+		if nodeID != id {
+			addrs = append(addrs, addr)
+		}
+	}
+	conn := gorqlite.Open(addrs)
+	if err := conn.RemoveNode(ctx, fmt.Sprintf("%d", id)); err != nil {
+		return wrapError(err, "failed to remove node %d", id)
+	}
+
+	delete(c.nodes, id)
+	if err := node.Close(); err != nil {
+		return wrapError(err, "failed to close node %d", id)
+	}
+	return nil
+}
+
+// StopNode stops the node without removing it from the cluster, such as to
+// simulate the node failing.
+func (c *Cluster) StopNode(id uint32) error {
+	node, ok := c.nodes[id]
+	if !ok {
+		return newError("unknown node: %d", id)
+	}
+	delete(c.nodes, id)
+	if err := node.Close(); err != nil {
+		return wrapError(err, "failed to stop node %d", id)
 	}
+	return nil
 }
 
 func (c *Cluster) Close() error {
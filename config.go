@@ -1,15 +1,87 @@
 package gorqlite
 
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/url"
+	"time"
+)
+
 type config struct {
-	ActiveHostRoundRobin bool
+	ActiveHostRoundRobin  bool
+	TLSConfig             *tls.Config
+	RootCAs               *x509.CertPool
+	ClientCertificates    []tls.Certificate
+	InsecureSkipVerify    bool
+	Username              string
+	Password              string
+	HasAuth               bool
+	LeaderRedirect        bool
+	NodeDiscoveryInterval time.Duration
+	RetryPolicy           RetryPolicy
 }
 
 // defaultConfig returns the default configuration which is used as a base
 // for all `Option` overrides.
 func defaultConfig() *config {
 	return &config{
-		ActiveHostRoundRobin: true,
+		ActiveHostRoundRobin:  true,
+		TLSConfig:             nil,
+		RootCAs:               nil,
+		ClientCertificates:    nil,
+		InsecureSkipVerify:    false,
+		Username:              "",
+		Password:              "",
+		HasAuth:               false,
+		LeaderRedirect:        false,
+		NodeDiscoveryInterval: 0,
+		RetryPolicy:           DefaultRetryPolicy(),
+	}
+}
+
+// newConfig returns the default configuration with opts applied.
+func newConfig(opts ...Option) *config {
+	conf := defaultConfig()
+	for _, opt := range opts {
+		opt(conf)
+	}
+	return conf
+}
+
+// tlsEnabled returns true if any TLS option is set, in which case hosts
+// without a scheme default to HTTPS.
+func (conf *config) tlsEnabled() bool {
+	return conf.TLSConfig != nil ||
+		conf.RootCAs != nil ||
+		len(conf.ClientCertificates) > 0 ||
+		conf.InsecureSkipVerify
+}
+
+// tls returns the TLS configuration built from the TLS options, or nil if
+// none are set.
+func (conf *config) tls() *tls.Config {
+	if !conf.tlsEnabled() {
+		return nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+	if conf.TLSConfig != nil {
+		tlsConfig = conf.TLSConfig.Clone()
+	}
+	if conf.RootCAs != nil {
+		tlsConfig.RootCAs = conf.RootCAs
+	}
+	if len(conf.ClientCertificates) > 0 {
+		tlsConfig.Certificates = append(
+			tlsConfig.Certificates, conf.ClientCertificates...,
+		)
+	}
+	if conf.InsecureSkipVerify {
+		tlsConfig.InsecureSkipVerify = true
 	}
+	return tlsConfig
 }
 
 // Options overrides the default configuration used for each request.
@@ -29,49 +101,450 @@ func WithActiveHostRoundRobin(enabled bool) Option {
 	}
 }
 
+// WithTLSConfig sets the TLS configuration used to connect to nodes over
+// HTTPS. The other TLS options (such as WithRootCAs) are applied on top of
+// this configuration.
+//
+// When any TLS option is set, hosts given to Open without a scheme use
+// HTTPS.
+func WithTLSConfig(tlsConfig *tls.Config) Option {
+	return func(conf *config) {
+		conf.TLSConfig = tlsConfig
+	}
+}
+
+// WithRootCAs sets the CA certificates used to verify the nodes
+// certificates, such as when rqlite uses a self-signed certificate. If not
+// set the system CA certificates are used.
+func WithRootCAs(rootCAs *x509.CertPool) Option {
+	return func(conf *config) {
+		conf.RootCAs = rootCAs
+	}
+}
+
+// WithClientCertificate adds a client certificate to present to nodes for
+// mutual TLS. The certificate can be loaded with tls.LoadX509KeyPair.
+func WithClientCertificate(cert tls.Certificate) Option {
+	return func(conf *config) {
+		conf.ClientCertificates = append(conf.ClientCertificates, cert)
+	}
+}
+
+// WithInsecureSkipVerify disables verifying the nodes certificates. This
+// should only be used in development.
+//
+// Disabled by default.
+func WithInsecureSkipVerify(insecureSkipVerify bool) Option {
+	return func(conf *config) {
+		conf.InsecureSkipVerify = insecureSkipVerify
+	}
+}
+
+// WithBasicAuth sets the basic auth credentials sent with every request,
+// for when rqlite is configured with an auth file.
+// See https://github.com/rqlite/rqlite/blob/cc74ab0af7c128582b7f0fd380033d43e642a121/DOC/SECURITY.md.
+//
+// Credentials can also be set per host by using the format
+// user:pass@host[:port] in Open, which take precedence over these
+// credentials.
+func WithBasicAuth(username string, password string) Option {
+	return func(conf *config) {
+		conf.Username = username
+		conf.Password = password
+		conf.HasAuth = true
+	}
+}
+
+// WithLeaderRedirect sends requests that must be handled by the leader
+// (executes and queries with consistency other than none) directly to the
+// leader, rather than relying on the node forwarding the request to the
+// leader. This saves a network hop for each of these requests.
+//
+// When enabled the redirect query parameter is added to these requests, so
+// if the node is not the leader it responds with a redirect to the leader.
+// The client follows the redirect and caches the leader for subsequent
+// requests. The cache is cleared if the leader fails, and updated if the
+// cached leader redirects again (such as after an election).
+// See https://github.com/rqlite/rqlite/blob/cc74ab0af7c128582b7f0fd380033d43e642a121/DOC/DATA_API.md#disabling-request-forwarding.
+//
+// Disabled by default.
+func WithLeaderRedirect(enabled bool) Option {
+	return func(conf *config) {
+		conf.LeaderRedirect = enabled
+	}
+}
+
+// WithNodeDiscovery periodically fetches the nodes in the cluster from the
+// nodes API and sends requests to all discovered nodes, so only the address
+// of a single node needs to be given to Open. Nodes removed from the cluster
+// are no longer used, though the hosts given to Open are always kept.
+// See https://github.com/rqlite/rqlite/blob/cc74ab0af7c128582b7f0fd380033d43e642a121/DOC/DIAGNOSTICS.md#nodes-api.
+//
+// The nodes are fetched in the background every interval, so Close must be
+// called to stop fetching.
+//
+// Disabled by default (or if interval is 0).
+func WithNodeDiscovery(interval time.Duration) Option {
+	return func(conf *config) {
+		conf.NodeDiscoveryInterval = interval
+	}
+}
+
+// WithRetryPolicy sets how failed requests are retried.
+//
+// Defaults to DefaultRetryPolicy().
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(conf *config) {
+		conf.RetryPolicy = policy
+	}
+}
+
 type queryConfig struct {
-	Consistency string
+	consistencyConfig
+	Associative bool
+	Timings     *Timings
 }
 
 func defaultQueryConfig() *queryConfig {
 	return &queryConfig{
-		Consistency: "",
+		consistencyConfig: consistencyConfig{
+			Consistency:     "",
+			Freshness:       0,
+			FreshnessStrict: false,
+		},
+		Associative: false,
+		Timings:     nil,
 	}
 }
 
-type QueryOption func(conf *queryConfig)
+func newQueryConfig(opts ...QueryOption) *queryConfig {
+	conf := defaultQueryConfig()
+	for _, opt := range opts {
+		opt.applyQuery(conf)
+	}
+	return conf
+}
+
+// QueryOption is an option for queries.
+type QueryOption interface {
+	applyQuery(conf *queryConfig)
+}
+
+type queryOptionFunc func(conf *queryConfig)
+
+func (f queryOptionFunc) applyQuery(conf *queryConfig) {
+	f(conf)
+}
+
+// QueryRequestOption is an option for both queries and requests.
+type QueryRequestOption interface {
+	QueryOption
+	RequestOption
+}
+
+// ConsistencyLevel is the read consistency level of a query.
+// See https://github.com/rqlite/rqlite/blob/cc74ab0af7c128582b7f0fd380033d43e642a121/DOC/CONSISTENCY.md.
+type ConsistencyLevel string
+
+const (
+	// ConsistencyNone reads from the local database of the node that
+	// receives the query, so may return stale data. The staleness can be
+	// bounded with WithFreshness.
+	ConsistencyNone ConsistencyLevel = "none"
+	// ConsistencyWeak reads from the leader, though may return stale data
+	// if the node has recently lost leadership.
+	ConsistencyWeak ConsistencyLevel = "weak"
+	// ConsistencyStrong reads through the Raft log, so always returns up to
+	// date data.
+	ConsistencyStrong ConsistencyLevel = "strong"
+	// ConsistencyLinearizable confirms the node is still the leader before
+	// reading, so always returns up to date data without writing to the Raft
+	// log. This requires a version of rqlite that supports it.
+	ConsistencyLinearizable ConsistencyLevel = "linearizable"
+)
+
+func (l ConsistencyLevel) valid() bool {
+	switch l {
+	case "", ConsistencyNone, ConsistencyWeak, ConsistencyStrong, ConsistencyLinearizable:
+		return true
+	default:
+		return false
+	}
+}
+
+// consistencyConfig configures the read consistency of queries and
+// requests.
+type consistencyConfig struct {
+	Consistency     ConsistencyLevel
+	Freshness       time.Duration
+	FreshnessStrict bool
+}
+
+// validate returns an error if the consistency options are invalid, so they
+// are rejected before sending the request.
+func (c *consistencyConfig) validate() error {
+	if !c.Consistency.valid() {
+		return newError("invalid consistency level: %s", c.Consistency)
+	}
+	if c.Freshness < 0 {
+		return newError("invalid freshness: %s", c.Freshness)
+	}
+	if c.Freshness > 0 && c.Consistency != ConsistencyNone {
+		return newError("freshness requires consistency level none")
+	}
+	if c.FreshnessStrict && c.Freshness == 0 {
+		return newError("strict freshness requires freshness")
+	}
+	return nil
+}
+
+// addQuery adds the consistency query parameters.
+func (c *consistencyConfig) addQuery(query url.Values) {
+	if c.Consistency != "" {
+		query.Add("level", string(c.Consistency))
+	}
+	if c.Freshness > 0 {
+		query.Add("freshness", c.Freshness.String())
+	}
+	if c.FreshnessStrict {
+		query.Add("freshness_strict", "")
+	}
+}
+
+type consistencyOptionFunc func(conf *consistencyConfig)
+
+func (f consistencyOptionFunc) applyQuery(conf *queryConfig) {
+	f(&conf.consistencyConfig)
+}
+
+func (f consistencyOptionFunc) applyRequest(conf *requestConfig) {
+	f(&conf.consistencyConfig)
+}
 
 // WithConsistency sets the level query parameter if set, otherwise it is not
 // set (so rqlite will default to weak consistency).
 // See https://github.com/rqlite/rqlite/blob/cc74ab0af7c128582b7f0fd380033d43e642a121/DOC/CONSISTENCY.md.
-func WithConsistency(consistency string) QueryOption {
-	return func(conf *queryConfig) {
+//
+// Unknown levels are rejected before sending the request.
+func WithConsistency(consistency ConsistencyLevel) QueryRequestOption {
+	return consistencyOptionFunc(func(conf *consistencyConfig) {
 		conf.Consistency = consistency
-	}
+	})
+}
+
+// WithFreshness sets the maximum time since the node last heard from the
+// leader for queries with consistency level none. If the node hasn't heard
+// from the leader within freshness the query fails rather than returning
+// stale data. If 0 the freshness is not checked.
+// See https://github.com/rqlite/rqlite/blob/cc74ab0af7c128582b7f0fd380033d43e642a121/DOC/CONSISTENCY.md#limiting-read-staleness.
+//
+// This must be used with WithConsistency(ConsistencyNone).
+func WithFreshness(freshness time.Duration) QueryRequestOption {
+	return consistencyOptionFunc(func(conf *consistencyConfig) {
+		conf.Freshness = freshness
+	})
+}
+
+// WithFreshnessStrict additionally checks the data was last updated within
+// the freshness set with WithFreshness, rather than only checking when the
+// node last heard from the leader. This requires a version of rqlite that
+// supports it.
+//
+// Disabled by default.
+func WithFreshnessStrict(strict bool) QueryRequestOption {
+	return consistencyOptionFunc(func(conf *consistencyConfig) {
+		conf.FreshnessStrict = strict
+	})
+}
+
+// WithAssociative requests results in rqlite's associative format, where each
+// row is an object mapping column names to values. The results are decoded
+// into the same QueryResult, with columns ordered by name, so rows should be
+// read by column name (such as with ScanStruct or Get) rather than position.
+// See https://github.com/rqlite/rqlite/blob/cc74ab0af7c128582b7f0fd380033d43e642a121/DOC/DATA_API.md.
+func WithAssociative(associative bool) QueryRequestOption {
+	return associativeOption{associative: associative}
+}
+
+type associativeOption struct {
+	associative bool
+}
+
+func (o associativeOption) applyQuery(conf *queryConfig) {
+	conf.Associative = o.associative
+}
+
+func (o associativeOption) applyRequest(conf *requestConfig) {
+	conf.Associative = o.associative
 }
 
 type executeConfig struct {
-	Transaction bool
+	Transaction  bool
+	Queue        *QueuedWrite
+	QueueWait    bool
+	QueueTimeout time.Duration
+	Timings      *Timings
 }
 
 func defaultExecuteConfig() *executeConfig {
 	return &executeConfig{
-		Transaction: false,
+		Transaction:  false,
+		Queue:        nil,
+		QueueWait:    false,
+		QueueTimeout: 0,
+		Timings:      nil,
+	}
+}
+
+func newExecuteConfig(opts ...ExecuteOption) *executeConfig {
+	conf := defaultExecuteConfig()
+	for _, opt := range opts {
+		opt.applyExecute(conf)
 	}
+	return conf
+}
+
+// ExecuteOption is an option for executes.
+type ExecuteOption interface {
+	applyExecute(conf *executeConfig)
 }
 
-type ExecuteOption func(conf *executeConfig)
+type executeOptionFunc func(conf *executeConfig)
+
+func (f executeOptionFunc) applyExecute(conf *executeConfig) {
+	f(conf)
+}
 
 // WithTransaction sets the transaction query parameter when enabled.
 // See https://github.com/rqlite/rqlite/blob/cc74ab0af7c128582b7f0fd380033d43e642a121/DOC/DATA_API.md#transactions.
 //
 // Disabed by default.
-func WithTransaction(transaction bool) ExecuteOption {
-	return func(conf *executeConfig) {
-		conf.Transaction = transaction
+func WithTransaction(transaction bool) ExecuteRequestOption {
+	return transactionOption{transaction: transaction}
+}
+
+// WithQueue queues the statements rather than waiting for them to be
+// written, which gives much higher write throughput, when queued is not nil.
+// Once rqlite has queued the statements, queued is set to the sequence
+// number rqlite assigned.
+// See https://rqlite.io/docs/api/queued-writes/.
+//
+// Since the statements have not been written when the execute returns, no
+// results are returned.
+//
+// Disabled by default.
+func WithQueue(queued *QueuedWrite) ExecuteOption {
+	return executeOptionFunc(func(conf *executeConfig) {
+		conf.Queue = queued
+	})
+}
+
+// WithQueueWait waits until the queue containing the statements has been
+// written when using WithQueue.
+//
+// Disabled by default.
+func WithQueueWait(wait bool) ExecuteOption {
+	return executeOptionFunc(func(conf *executeConfig) {
+		conf.QueueWait = wait
+	})
+}
+
+// WithQueueTimeout sets the maximum time rqlite waits for the queue to be
+// written when using WithQueueWait. If 0 the rqlite default is used.
+func WithQueueTimeout(timeout time.Duration) ExecuteOption {
+	return executeOptionFunc(func(conf *executeConfig) {
+		conf.QueueTimeout = timeout
+	})
+}
+
+// ExecuteRequestOption is an option for both executes and requests.
+type ExecuteRequestOption interface {
+	ExecuteOption
+	RequestOption
+}
+
+type transactionOption struct {
+	transaction bool
+}
+
+func (o transactionOption) applyExecute(conf *executeConfig) {
+	conf.Transaction = o.transaction
+}
+
+func (o transactionOption) applyRequest(conf *requestConfig) {
+	conf.Transaction = o.transaction
+}
+
+type requestConfig struct {
+	consistencyConfig
+	Transaction bool
+	Associative bool
+	Timings     *Timings
+}
+
+func defaultRequestConfig() *requestConfig {
+	return &requestConfig{
+		consistencyConfig: consistencyConfig{
+			Consistency:     "",
+			Freshness:       0,
+			FreshnessStrict: false,
+		},
+		Transaction: false,
+		Associative: false,
+		Timings:     nil,
 	}
 }
 
+func newRequestConfig(opts ...RequestOption) *requestConfig {
+	conf := defaultRequestConfig()
+	for _, opt := range opts {
+		opt.applyRequest(conf)
+	}
+	return conf
+}
+
+// RequestOption is an option for requests, which may contain both queries
+// and executes.
+type RequestOption interface {
+	applyRequest(conf *requestConfig)
+}
+
+// QueryExecuteOption is an option for queries and executes. It also applies
+// to requests, since they may contain both.
+type QueryExecuteOption interface {
+	QueryOption
+	ExecuteOption
+	RequestOption
+}
+
+type timingsOption struct {
+	timings *Timings
+}
+
+func (o timingsOption) applyQuery(conf *queryConfig) {
+	conf.Timings = o.timings
+}
+
+func (o timingsOption) applyExecute(conf *executeConfig) {
+	conf.Timings = o.timings
+}
+
+func (o timingsOption) applyRequest(conf *requestConfig) {
+	conf.Timings = o.timings
+}
+
+// WithTimings requests timing information from rqlite when timings is not
+// nil. The time rqlite took to process each statement is set in the Time
+// field of each result, and timings is set to the time rqlite took to
+// process the whole request along with the round-trip time of each attempt
+// to send the request as measured by the client.
+// See https://github.com/rqlite/rqlite/blob/cc74ab0af7c128582b7f0fd380033d43e642a121/DOC/DATA_API.md.
+//
+// Disabled by default.
+func WithTimings(timings *Timings) QueryExecuteOption {
+	return timingsOption{timings: timings}
+}
+
 type nodesConfig struct {
 	NonVoters bool
 }
@@ -93,3 +566,200 @@ func WithNonVoters(nonVoters bool) NodesOption {
 		conf.NonVoters = nonVoters
 	}
 }
+
+type scanConfig struct {
+	IgnoreUnknownColumns bool
+}
+
+func defaultScanConfig() *scanConfig {
+	return &scanConfig{
+		IgnoreUnknownColumns: false,
+	}
+}
+
+type ScanOption func(conf *scanConfig)
+
+// WithIgnoreUnknownColumns ignores columns that don't match a struct field
+// when scanning into a struct, rather than returning an error.
+//
+// Disabled by default.
+func WithIgnoreUnknownColumns(ignore bool) ScanOption {
+	return func(conf *scanConfig) {
+		conf.IgnoreUnknownColumns = ignore
+	}
+}
+
+type batchConfig struct {
+	MaxStatements int
+	MaxBytes      int
+}
+
+func defaultBatchConfig() *batchConfig {
+	return &batchConfig{
+		MaxStatements: 1000,
+		MaxBytes:      1 << 20,
+	}
+}
+
+type BatchOption func(conf *batchConfig)
+
+// WithBatchMaxStatements sets the maximum number of statements written in
+// each request by a BatchWriter, or 0 for no maximum.
+//
+// Defaults to 1000.
+func WithBatchMaxStatements(maxStatements int) BatchOption {
+	return func(conf *batchConfig) {
+		conf.MaxStatements = maxStatements
+	}
+}
+
+// WithBatchMaxBytes sets the maximum size of the request body in bytes
+// written by a BatchWriter, or 0 for no maximum. A single statement larger
+// than the maximum is still written in its own request.
+//
+// Defaults to 1MB.
+func WithBatchMaxBytes(maxBytes int) BatchOption {
+	return func(conf *batchConfig) {
+		conf.MaxBytes = maxBytes
+	}
+}
+
+// BackupFormat is the format of a backup.
+type BackupFormat string
+
+const (
+	// BackupFormatBinary is a SQLite database file.
+	BackupFormatBinary BackupFormat = "binary"
+	// BackupFormatSQL is a SQL text dump of the database.
+	BackupFormatSQL BackupFormat = "sql"
+)
+
+type backupConfig struct {
+	Format     BackupFormat
+	Compress   bool
+	LeaderOnly bool
+}
+
+func defaultBackupConfig() *backupConfig {
+	return &backupConfig{
+		Format:     BackupFormatBinary,
+		Compress:   false,
+		LeaderOnly: true,
+	}
+}
+
+type BackupOption func(conf *backupConfig)
+
+// WithBackupFormat sets the format of the backup.
+//
+// Defaults to BackupFormatBinary.
+func WithBackupFormat(format BackupFormat) BackupOption {
+	return func(conf *backupConfig) {
+		conf.Format = format
+	}
+}
+
+// WithBackupCompress requests the backup is gzip compressed by rqlite, in
+// which case the compressed backup is written.
+//
+// Disabled by default.
+func WithBackupCompress(compress bool) BackupOption {
+	return func(conf *backupConfig) {
+		conf.Compress = compress
+	}
+}
+
+// WithBackupLeaderOnly requires the backup to be taken by the leader. If
+// disabled the backup may be taken by any node, which may be missing recent
+// writes.
+//
+// Enabled by default.
+func WithBackupLeaderOnly(leaderOnly bool) BackupOption {
+	return func(conf *backupConfig) {
+		conf.LeaderOnly = leaderOnly
+	}
+}
+
+type readyConfig struct {
+	NoLeader     bool
+	Sync         bool
+	SyncTimeout  time.Duration
+	Quorum       bool
+	PollInterval time.Duration
+}
+
+func defaultReadyConfig() *readyConfig {
+	return &readyConfig{
+		NoLeader:     false,
+		Sync:         false,
+		SyncTimeout:  0,
+		Quorum:       false,
+		PollInterval: 250 * time.Millisecond,
+	}
+}
+
+func newReadyConfig(opts ...ReadyOption) *readyConfig {
+	conf := defaultReadyConfig()
+	for _, opt := range opts {
+		opt(conf)
+	}
+	return conf
+}
+
+// query returns the readiness check query parameters.
+func (c *readyConfig) query() url.Values {
+	query := url.Values{}
+	if c.NoLeader {
+		query.Add("noleader", "")
+	}
+	if c.Sync {
+		query.Add("sync", "")
+		if c.SyncTimeout > 0 {
+			query.Add("timeout", c.SyncTimeout.String())
+		}
+	}
+	return query
+}
+
+type ReadyOption func(conf *readyConfig)
+
+// WithReadyNoLeader considers a node ready even if it has no leader.
+//
+// Disabled by default.
+func WithReadyNoLeader(noLeader bool) ReadyOption {
+	return func(conf *readyConfig) {
+		conf.NoLeader = noLeader
+	}
+}
+
+// WithReadySync waits for the node to apply all entries in the Raft log
+// that were committed when it received the request before responding, up
+// to timeout (or the rqlite default if 0).
+//
+// Disabled by default.
+func WithReadySync(sync bool, timeout time.Duration) ReadyOption {
+	return func(conf *readyConfig) {
+		conf.Sync = sync
+		conf.SyncTimeout = timeout
+	}
+}
+
+// WithReadyQuorum makes WaitUntilReady wait until a quorum (a majority) of
+// the known hosts are ready, rather than every host.
+//
+// Disabled by default.
+func WithReadyQuorum(quorum bool) ReadyOption {
+	return func(conf *readyConfig) {
+		conf.Quorum = quorum
+	}
+}
+
+// WithReadyPollInterval sets how often WaitUntilReady checks whether the
+// hosts are ready.
+//
+// Defaults to 250ms.
+func WithReadyPollInterval(interval time.Duration) ReadyOption {
+	return func(conf *readyConfig) {
+		conf.PollInterval = interval
+	}
+}
@@ -0,0 +1,98 @@
+package gorqlite
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// nodeDiscovery periodically refreshes the hosts used by an httpAPIClient
+// from the nodes API, so the client discovers nodes added to the cluster and
+// stops using nodes removed from the cluster.
+type nodeDiscovery struct {
+	nodes    func(ctx context.Context) (Nodes, error)
+	api      *httpAPIClient
+	interval time.Duration
+
+	done      chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+func newNodeDiscovery(nodes func(ctx context.Context) (Nodes, error),
+	api *httpAPIClient,
+	interval time.Duration) *nodeDiscovery {
+	return &nodeDiscovery{
+		nodes:    nodes,
+		api:      api,
+		interval: interval,
+		done:     make(chan struct{}),
+	}
+}
+
+// Start refreshes the hosts in the background every interval until Close
+// is called. The first refresh runs immediately.
+func (d *nodeDiscovery) Start() {
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+
+		ticker := time.NewTicker(d.interval)
+		defer ticker.Stop()
+
+		for {
+			ctx, cancel := context.WithTimeout(context.Background(), d.interval)
+			// Errors are ignored as the client still has the existing hosts,
+			// and the refresh is retried on the next interval.
+			_ = d.Refresh(ctx)
+			cancel()
+
+			select {
+			case <-d.done:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Refresh fetches the nodes in the cluster and updates the clients hosts
+// with their API addresses.
+func (d *nodeDiscovery) Refresh(ctx context.Context) error {
+	// Cancel the request if the discovery is closed.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-d.done:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	nodes, err := d.nodes(ctx)
+	if err != nil {
+		return wrapError(err, "failed to discover nodes")
+	}
+
+	addrs := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		if node.APIAddr != "" {
+			addrs = append(addrs, node.APIAddr)
+		}
+	}
+	// Sort so the order of hosts is stable across refreshes.
+	sort.Strings(addrs)
+	d.api.setDiscoveredHosts(addrs)
+	return nil
+}
+
+// Close stops the background refresh and waits for it to exit. Close may be
+// called multiple times.
+func (d *nodeDiscovery) Close() {
+	d.closeOnce.Do(func() {
+		close(d.done)
+	})
+	d.wg.Wait()
+}
@@ -0,0 +1,142 @@
+package gorqlite
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNodeDiscovery_RefreshMergesSeeds(t *testing.T) {
+	api := newHTTPAPIClient(
+		[]string{"rqlite-0", "alice:secret@rqlite-1"},
+		newCountingRoundTripper(nil),
+		&nopClock{},
+		newConfig(),
+	)
+
+	nodes := Nodes{
+		"1": {APIAddr: "http://rqlite-1"},
+		"2": {APIAddr: "https://rqlite-2:4001"},
+		"3": {APIAddr: "http://rqlite-3"},
+		// Nodes without an API address are ignored.
+		"4": {APIAddr: ""},
+	}
+	discovery := newNodeDiscovery(
+		func(ctx context.Context) (Nodes, error) {
+			return nodes, nil
+		},
+		api,
+		0,
+	)
+
+	require.Nil(t, discovery.Refresh(context.Background()))
+	require.Equal(t, []apiHost{
+		{Scheme: "http", Host: "rqlite-0"},
+		// Seeds keep their credentials.
+		{Scheme: "http", Host: "rqlite-1", Username: "alice", Password: "secret", HasAuth: true},
+		{Scheme: "http", Host: "rqlite-3"},
+		{Scheme: "https", Host: "rqlite-2:4001"},
+	}, api.currentHosts())
+
+	// Removed nodes should be dropped, though seeds are always kept.
+	nodes = Nodes{
+		"3": {APIAddr: "http://rqlite-3"},
+		"5": {APIAddr: "http://rqlite-5"},
+	}
+	require.Nil(t, discovery.Refresh(context.Background()))
+	require.Equal(t, []apiHost{
+		{Scheme: "http", Host: "rqlite-0"},
+		{Scheme: "http", Host: "rqlite-1", Username: "alice", Password: "secret", HasAuth: true},
+		{Scheme: "http", Host: "rqlite-3"},
+		{Scheme: "http", Host: "rqlite-5"},
+	}, api.currentHosts())
+}
+
+func TestNodeDiscovery_RefreshFailsKeepsHosts(t *testing.T) {
+	api := newHTTPAPIClient(
+		[]string{"rqlite-0"}, newCountingRoundTripper(nil), &nopClock{}, newConfig(),
+	)
+
+	var nodesErr error
+	discovery := newNodeDiscovery(
+		func(ctx context.Context) (Nodes, error) {
+			if nodesErr != nil {
+				return nil, nodesErr
+			}
+			return Nodes{"1": {APIAddr: "http://rqlite-1"}}, nil
+		},
+		api,
+		0,
+	)
+	require.Nil(t, discovery.Refresh(context.Background()))
+
+	nodesErr = fmt.Errorf("network error")
+	require.Error(t, discovery.Refresh(context.Background()))
+	require.Equal(t, []apiHost{
+		{Scheme: "http", Host: "rqlite-0"},
+		{Scheme: "http", Host: "rqlite-1"},
+	}, api.currentHosts())
+}
+
+func TestNodeDiscovery_DiscoveredHostsReceiveRequests(t *testing.T) {
+	transport := newCountingRoundTripper(nil)
+	api := newHTTPAPIClient(
+		[]string{"rqlite-0"}, transport, &nopClock{}, newConfig(),
+	)
+	discovery := newNodeDiscovery(
+		func(ctx context.Context) (Nodes, error) {
+			return Nodes{
+				"1": {APIAddr: "http://rqlite-1"},
+				"2": {APIAddr: "http://rqlite-2"},
+			}, nil
+		},
+		api,
+		0,
+	)
+	require.Nil(t, discovery.Refresh(context.Background()))
+
+	for i := 0; i != 6; i++ {
+		resp, err := api.Get("/status", nil)
+		require.Nil(t, err)
+		resp.Body.Close()
+	}
+	require.Equal(t, 2, transport.Requests("rqlite-0"))
+	require.Equal(t, 2, transport.Requests("rqlite-1"))
+	require.Equal(t, 2, transport.Requests("rqlite-2"))
+}
+
+func TestNodeDiscovery_StartThenClose(t *testing.T) {
+	api := newHTTPAPIClient(
+		[]string{"rqlite-0"}, newCountingRoundTripper(nil), &nopClock{}, newConfig(),
+	)
+
+	refreshed := make(chan struct{}, 1)
+	discovery := newNodeDiscovery(
+		func(ctx context.Context) (Nodes, error) {
+			select {
+			case refreshed <- struct{}{}:
+			default:
+			}
+			return Nodes{"1": {APIAddr: "http://rqlite-1"}}, nil
+		},
+		api,
+		// Use a long interval so only the initial refresh runs.
+		time.Hour,
+	)
+	discovery.Start()
+
+	// The first refresh should run immediately.
+	<-refreshed
+
+	discovery.Close()
+	// Closing multiple times should be a no-op.
+	discovery.Close()
+
+	require.Equal(t, []apiHost{
+		{Scheme: "http", Host: "rqlite-0"},
+		{Scheme: "http", Host: "rqlite-1"},
+	}, api.currentHosts())
+}
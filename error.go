@@ -1,32 +1,207 @@
 package gorqlite
 
 import (
+	"errors"
 	"fmt"
+	"net/http"
+	"strings"
+	"time"
 )
 
-type gorqliteError struct {
-	Inner   string
+// ErrNoHosts is returned when a request is made with no hosts to send the
+// request to.
+var ErrNoHosts = errors.New("no hosts")
+
+// ErrUnknownColumn is returned when getting a column by name that is not in
+// the query result.
+var ErrUnknownColumn = errors.New("unknown column")
+
+// ErrUnknownNode is returned when removing a node that is not a member of
+// the cluster.
+var ErrUnknownNode = errors.New("unknown node")
+
+// Error is the error returned by gorqlite. Err is the underlying cause (which
+// may be nil), which can be inspected with errors.Is and errors.As.
+type Error struct {
 	Message string
+	Err     error
 }
 
-func newError(messagef string, msgArgs ...interface{}) *gorqliteError {
-	return &gorqliteError{
-		Inner:   "",
+func newError(messagef string, msgArgs ...interface{}) *Error {
+	return &Error{
 		Message: fmt.Sprintf(messagef, msgArgs...),
+		Err:     nil,
 	}
 }
 
-func wrapError(err error, messagef string) *gorqliteError {
-	return &gorqliteError{
-		Inner:   err.Error(),
+func wrapError(err error, messagef string) *Error {
+	return &Error{
 		Message: messagef,
+		Err:     err,
 	}
 }
 
-func (err gorqliteError) Error() string {
+func (err *Error) Error() string {
 	s := err.Message
-	if err.Inner != "" {
-		return fmt.Sprintf("%s: %s", s, err.Inner)
+	if err.Err != nil {
+		return fmt.Sprintf("%s: %s", s, err.Err)
+	}
+	return s
+}
+
+func (err *Error) Unwrap() error {
+	return err.Err
+}
+
+// StatusError is returned when a node responds with an unexpected HTTP
+// status code.
+type StatusError struct {
+	// Host is the address of the node that responded (excluding any
+	// credentials).
+	Host       string
+	StatusCode int
+	// Body is the start of the response body, which may contain an error
+	// message from rqlite.
+	Body string
+}
+
+func (err *StatusError) Error() string {
+	s := fmt.Sprintf("bad status code: %d", err.StatusCode)
+	if err.Host != "" {
+		s = fmt.Sprintf("%s: host %s", s, err.Host)
+	}
+	if err.Body != "" {
+		s = fmt.Sprintf("%s: %s", s, err.Body)
+	}
+	return s
+}
+
+// NotLeaderError is returned when a request that must be handled by the
+// leader was rejected by a node that is not the leader, such as when the
+// node redirected to the leader or the cluster has no leader.
+type NotLeaderError struct {
+	// Host is the address of the node that rejected the request (excluding
+	// any credentials), which may be empty if unknown.
+	Host string
+	// Err is the response from the node, which is a *StatusError.
+	Err error
+}
+
+func (err *NotLeaderError) Error() string {
+	return fmt.Sprintf("not leader: %s", err.Err)
+}
+
+func (err *NotLeaderError) Unwrap() error {
+	return err.Err
+}
+
+// notLeaderError returns a NotLeaderError if err is caused by a node
+// responding that it is not the leader, otherwise returns err.
+func notLeaderError(err error) error {
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) {
+		return err
+	}
+	if !isRedirect(statusErr.StatusCode) &&
+		!strings.Contains(strings.ToLower(statusErr.Body), "not leader") &&
+		!strings.Contains(strings.ToLower(statusErr.Body), "leader not found") {
+		return err
+	}
+	return &NotLeaderError{
+		Host: statusErr.Host,
+		Err:  statusErr,
+	}
+}
+
+// NotReadyError is returned when a node is not ready to handle requests.
+type NotReadyError struct {
+	// Host is the address of the node (excluding any credentials), which may
+	// be empty if unknown.
+	Host string
+	// Reason describes the checks that failed, as reported by the node (such
+	// as "[-]leader not ok").
+	Reason string
+}
+
+func (err *NotReadyError) Error() string {
+	s := "not ready"
+	if err.Host != "" {
+		s = fmt.Sprintf("%s: host %s", s, err.Host)
+	}
+	if err.Reason != "" {
+		s = fmt.Sprintf("%s: %s", s, err.Reason)
 	}
 	return s
 }
+
+// notReadyError returns a NotReadyError if err is caused by a node
+// responding that it is not ready, otherwise returns err.
+func notReadyError(err error) error {
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusServiceUnavailable {
+		return err
+	}
+	return &NotReadyError{
+		Host:   statusErr.Host,
+		Reason: statusErr.Body,
+	}
+}
+
+// Attempt describes an attempt to send a request to a node.
+type Attempt struct {
+	// Host is the address of the node (excluding any credentials).
+	Host string
+	// StatusCode is the HTTP status code the node responded with, or 0 if
+	// the request failed without a response.
+	StatusCode int
+	// Err is the cause of the failure, or nil if the attempt succeeded. If
+	// the node responded with a bad status code this is a *StatusError.
+	Err error
+	// Duration is the round-trip time of the attempt measured by the client,
+	// from sending the request until receiving the response headers.
+	Duration time.Duration
+}
+
+func (a Attempt) String() string {
+	if a.StatusCode == 0 {
+		return fmt.Sprintf("%s: %s", a.Host, a.Err)
+	}
+
+	s := fmt.Sprintf("%s: status %d", a.Host, a.StatusCode)
+	var statusErr *StatusError
+	if errors.As(a.Err, &statusErr) {
+		// Only include the response body since the host and status are
+		// already included.
+		if statusErr.Body != "" {
+			s = fmt.Sprintf("%s: %s", s, statusErr.Body)
+		}
+	} else if a.Err != nil {
+		s = fmt.Sprintf("%s: %s", s, a.Err)
+	}
+	return s
+}
+
+// RetriesExhaustedError is returned when a request failed on every attempt.
+// Attempts lists each attempt in order.
+type RetriesExhaustedError struct {
+	Attempts []Attempt
+}
+
+func (err *RetriesExhaustedError) Error() string {
+	attempts := make([]string, 0, len(err.Attempts))
+	for _, a := range err.Attempts {
+		attempts = append(attempts, a.String())
+	}
+	return fmt.Sprintf(
+		"max retries exceeded after %d attempts: [%s]",
+		len(err.Attempts), strings.Join(attempts, "; "),
+	)
+}
+
+// Unwrap returns the cause of the last attempt.
+func (err *RetriesExhaustedError) Unwrap() error {
+	if len(err.Attempts) == 0 {
+		return nil
+	}
+	return err.Attempts[len(err.Attempts)-1].Err
+}
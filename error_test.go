@@ -0,0 +1,44 @@
+package gorqlite
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetriesExhaustedError_Error(t *testing.T) {
+	err := &RetriesExhaustedError{
+		Attempts: []Attempt{
+			{
+				Host:       "rqlite-0",
+				StatusCode: http.StatusServiceUnavailable,
+				Err: &StatusError{
+					Host:       "rqlite-0",
+					StatusCode: http.StatusServiceUnavailable,
+					Body:       "leader not found",
+				},
+			},
+			{
+				Host:       "rqlite-1",
+				StatusCode: http.StatusBadGateway,
+				Err: &StatusError{
+					Host:       "rqlite-1",
+					StatusCode: http.StatusBadGateway,
+					Body:       "",
+				},
+			},
+			{
+				Host:       "rqlite-2",
+				StatusCode: 0,
+				Err:        errors.New("connection refused"),
+			},
+		},
+	}
+	require.Equal(
+		t,
+		"max retries exceeded after 3 attempts: [rqlite-0: status 503: leader not found; rqlite-1: status 502; rqlite-2: connection refused]",
+		err.Error(),
+	)
+}
@@ -8,41 +8,72 @@ import (
 )
 
 // Gorqlite is a client for the rqlite API endpoints.
+//
+// Gorqlite is safe for concurrent use by multiple goroutines, so a single
+// client should be opened and shared rather than opening a client per
+// request.
 type Gorqlite struct {
 	apiClient APIClient
+	discovery *nodeDiscovery
 }
 
 // Open opens the gorqlite client. This will not attempt to connect to the
 // database.
 //
-// hosts is a list of addresses (in format host[:port]) for the known
-// nodes in the cluster.
+// hosts is a list of addresses (in format [scheme://][user:pass@]host[:port])
+// for the known nodes in the cluster. The scheme may be http or https. If the
+// scheme is omitted it defaults to https if any TLS option is set, otherwise
+// http. If user:pass is given it is used as the basic auth credentials for
+// that host (special characters must be percent-encoded). Invalid addresses
+// are ignored, and if no addresses are valid requests fail with an error
+// wrapping ErrNoHosts that describes why.
 //
 // opts is a list of opts to apply to every request.
 func Open(hosts []string, opts ...Option) *Gorqlite {
-	conf := defaultConfig()
-	for _, opt := range opts {
-		opt(conf)
-	}
-
+	conf := newConfig(opts...)
 	apiClient := newHTTPAPIClient(
-		hosts, http.DefaultTransport, &systemClock{}, conf.ActiveHostRoundRobin,
+		hosts, newHTTPTransport(conf), &systemClock{}, conf,
 	)
-	return &Gorqlite{
-		apiClient,
+	g := &Gorqlite{
+		apiClient: apiClient,
+		discovery: nil,
+	}
+
+	if conf.NodeDiscoveryInterval > 0 {
+		g.discovery = newNodeDiscovery(
+			func(ctx context.Context) (Nodes, error) {
+				return g.NodesWithContext(ctx, WithNonVoters(true))
+			},
+			apiClient,
+			conf.NodeDiscoveryInterval,
+		)
+		g.discovery.Start()
 	}
+
+	return g
 }
 
 // OpenWithClient opens a connection to rqlite using a custom API client.
 func OpenWithClient(apiClient APIClient, opts ...Option) *Gorqlite {
 	return &Gorqlite{
-		apiClient,
+		apiClient: apiClient,
+		discovery: nil,
 	}
 }
 
+// Close releases any resources used by the client, such as stopping node
+// discovery. The client should not be used after it is closed.
+func (g *Gorqlite) Close() error {
+	if g.discovery != nil {
+		g.discovery.Close()
+	}
+	return nil
+}
+
 type queryResponse struct {
 	Results []QueryResult `json:"results,omitempty"`
 	Error   string        `json:"error,omitempty"`
+	Time    float64       `json:"time,omitempty"`
 }
 
 // Query runs the given sql statements and returns the query result.
@@ -56,30 +87,25 @@ func (g *Gorqlite) Query(sql []string, opts ...QueryOption) (QueryResults, error
 }
 
 func (g *Gorqlite) QueryWithContext(ctx context.Context, sql []string, opts ...QueryOption) (QueryResults, error) {
-	conf := defaultQueryConfig()
-	for _, opt := range opts {
-		opt(conf)
-	}
+	return g.QueryStatementsWithContext(ctx, newStatements(sql), opts...)
+}
 
-	query := url.Values{}
-	if conf.Consistency != "" {
-		query.Add("consistency", conf.Consistency)
-	}
+// QueryStatements runs the given statements, which may include parameters,
+// and returns the query result.
+// See https://github.com/rqlite/rqlite/blob/cc74ab0af7c128582b7f0fd380033d43e642a121/DOC/DATA_API.md#parameterized-statements.
+func (g *Gorqlite) QueryStatements(stmts []Statement, opts ...QueryOption) (QueryResults, error) {
+	return g.QueryStatementsWithContext(context.Background(), stmts, opts...)
+}
 
-	body, err := json.Marshal(sql)
-	if err != nil {
-		return nil, wrapError(err, "query failed: failed to marshal query")
-	}
-	resp, err := g.apiClient.PostWithContext(ctx, "/db/query", query, body)
+func (g *Gorqlite) QueryStatementsWithContext(ctx context.Context, stmts []Statement, opts ...QueryOption) (QueryResults, error) {
+	conf := newQueryConfig(opts...)
+
+	resp, err := g.postQuery(ctx, stmts, conf)
 	if err != nil {
-		return nil, wrapError(err, "query failed: request failed")
+		return nil, err
 	}
 	defer resp.Body.Close()
 
-	if !isStatusOK(resp.StatusCode) {
-		return nil, newError("query failed: invalid status code: %d", resp.StatusCode)
-	}
-
 	var queryResp queryResponse
 	if err := json.NewDecoder(resp.Body).Decode(&queryResp); err != nil {
 		return nil, wrapError(err, "query failed: invalid response")
@@ -87,10 +113,77 @@ func (g *Gorqlite) QueryWithContext(ctx context.Context, sql []string, opts ...Q
 	if queryResp.Error != "" {
 		return nil, newError("query failed: %s", queryResp.Error)
 	}
+	if conf.Timings != nil {
+		conf.Timings.Time = secondsToDuration(queryResp.Time)
+	}
 
 	return queryResp.Results, nil
 }
 
+// QueryStream runs the given statement and returns an iterator over the
+// result rows, which decodes each row from the response as it is read rather
+// than loading the whole result into memory. This should be used for
+// queries that may return a large number of rows.
+//
+// The returned Rows must be closed. When using WithTimings the per statement
+// and request times are not available.
+func (g *Gorqlite) QueryStream(sql string, opts ...QueryOption) (*Rows, error) {
+	return g.QueryStreamWithContext(context.Background(), sql, opts...)
+}
+
+func (g *Gorqlite) QueryStreamWithContext(ctx context.Context, sql string, opts ...QueryOption) (*Rows, error) {
+	return g.QueryStatementStreamWithContext(ctx, NewStatement(sql), opts...)
+}
+
+// QueryStatementStream runs the given statement, which may include
+// parameters, and returns an iterator over the result rows. See QueryStream.
+func (g *Gorqlite) QueryStatementStream(stmt Statement, opts ...QueryOption) (*Rows, error) {
+	return g.QueryStatementStreamWithContext(context.Background(), stmt, opts...)
+}
+
+func (g *Gorqlite) QueryStatementStreamWithContext(ctx context.Context, stmt Statement, opts ...QueryOption) (*Rows, error) {
+	conf := newQueryConfig(opts...)
+
+	resp, err := g.postQuery(ctx, []Statement{stmt}, conf)
+	if err != nil {
+		return nil, err
+	}
+	return newRows(resp.Body)
+}
+
+// postQuery sends the query request and returns the response if it has an
+// OK status.
+func (g *Gorqlite) postQuery(ctx context.Context, stmts []Statement, conf *queryConfig) (*http.Response, error) {
+	if err := conf.validate(); err != nil {
+		return nil, wrapError(err, "query failed")
+	}
+
+	query := url.Values{}
+	conf.addQuery(query)
+	if conf.Associative {
+		query.Add("associative", "")
+	}
+	if conf.Timings != nil {
+		query.Add("timings", "")
+		ctx = conf.Timings.start(ctx)
+	}
+
+	body, err := json.Marshal(stmts)
+	if err != nil {
+		return nil, wrapError(err, "query failed: failed to marshal query")
+	}
+	resp, err := g.apiClient.PostWithContext(ctx, "/db/query", query, body)
+	if err != nil {
+		return nil, wrapError(err, "query failed: request failed")
+	}
+
+	if !isStatusOK(resp.StatusCode) {
+		defer resp.Body.Close()
+		return nil, wrapError(newStatusError("", resp), "query failed")
+	}
+	return resp, nil
+}
+
 func (g *Gorqlite) QueryOne(sql string, opts ...QueryOption) (QueryResult, error) {
 	return g.QueryOneWithContext(context.Background(), sql, opts...)
 }
@@ -107,8 +200,10 @@ func (g *Gorqlite) QueryOneWithContext(ctx context.Context, sql string, opts ...
 }
 
 type executeResponse struct {
-	Results []ExecuteResult `json:"results,omitempty"`
-	Error   string          `json:"error,omitempty"`
+	Results        []ExecuteResult `json:"results,omitempty"`
+	Error          string          `json:"error,omitempty"`
+	Time           float64         `json:"time,omitempty"`
+	SequenceNumber int64           `json:"sequence_number,omitempty"`
 }
 
 // Execute writes the sql statements to rqlite and returns the execute results.
@@ -117,22 +212,46 @@ type executeResponse struct {
 // To execute the statements within a transaction use WithTransaction(true)
 // option.
 // See https://github.com/rqlite/rqlite/blob/cc74ab0af7c128582b7f0fd380033d43e642a121/DOC/DATA_API.md#transactions.
+//
+// To queue the statements rather than waiting for them to be written use
+// WithQueue, in which case no results are returned.
 func (g *Gorqlite) Execute(sql []string, opts ...ExecuteOption) (ExecuteResults, error) {
 	return g.ExecuteWithContext(context.Background(), sql, opts...)
 }
 
 func (g *Gorqlite) ExecuteWithContext(ctx context.Context, sql []string, opts ...ExecuteOption) (ExecuteResults, error) {
-	conf := defaultExecuteConfig()
-	for _, opt := range opts {
-		opt(conf)
-	}
+	return g.ExecuteStatementsWithContext(ctx, newStatements(sql), opts...)
+}
+
+// ExecuteStatements writes the given statements, which may include
+// parameters, to rqlite and returns the execute results.
+// See https://github.com/rqlite/rqlite/blob/cc74ab0af7c128582b7f0fd380033d43e642a121/DOC/DATA_API.md#parameterized-statements.
+func (g *Gorqlite) ExecuteStatements(stmts []Statement, opts ...ExecuteOption) (ExecuteResults, error) {
+	return g.ExecuteStatementsWithContext(context.Background(), stmts, opts...)
+}
+
+func (g *Gorqlite) ExecuteStatementsWithContext(ctx context.Context, stmts []Statement, opts ...ExecuteOption) (ExecuteResults, error) {
+	conf := newExecuteConfig(opts...)
 
 	query := url.Values{}
 	if conf.Transaction {
 		query.Add("transaction", "")
 	}
+	if conf.Queue != nil {
+		query.Add("queue", "")
+		if conf.QueueWait {
+			query.Add("wait", "")
+			if conf.QueueTimeout > 0 {
+				query.Add("timeout", conf.QueueTimeout.String())
+			}
+		}
+	}
+	if conf.Timings != nil {
+		query.Add("timings", "")
+		ctx = conf.Timings.start(ctx)
+	}
 
-	body, err := json.Marshal(sql)
+	body, err := json.Marshal(stmts)
 	if err != nil {
 		return nil, wrapError(err, "execute failed: failed to marshal query")
 	}
@@ -143,7 +262,7 @@ func (g *Gorqlite) ExecuteWithContext(ctx context.Context, sql []string, opts ..
 	defer resp.Body.Close()
 
 	if !isStatusOK(resp.StatusCode) {
-		return nil, newError("execute failed: invalid status code: %d", resp.StatusCode)
+		return nil, wrapError(newStatusError("", resp), "execute failed")
 	}
 
 	var executeResp executeResponse
@@ -153,6 +272,12 @@ func (g *Gorqlite) ExecuteWithContext(ctx context.Context, sql []string, opts ..
 	if executeResp.Error != "" {
 		return nil, newError("execute failed: %s", executeResp.Error)
 	}
+	if conf.Timings != nil {
+		conf.Timings.Time = secondsToDuration(executeResp.Time)
+	}
+	if conf.Queue != nil {
+		conf.Queue.SequenceNumber = executeResp.SequenceNumber
+	}
 
 	return executeResp.Results, nil
 }
@@ -166,12 +291,93 @@ func (g *Gorqlite) ExecuteOneWithContext(ctx context.Context, sql string, opts .
 	if err != nil {
 		return ExecuteResult{}, err
 	}
+	// Queued writes don't return any results.
+	if len(results) == 0 && newExecuteConfig(opts...).Queue != nil {
+		return ExecuteResult{}, nil
+	}
 	if len(results) != 1 {
 		return ExecuteResult{}, newError("execute failed: expected one result")
 	}
 	return results[0], nil
 }
 
+type requestResponse struct {
+	Results []RequestResult `json:"results,omitempty"`
+	Error   string          `json:"error,omitempty"`
+	Time    float64         `json:"time,omitempty"`
+}
+
+// Request runs the given sql statements, which may contain both queries and
+// executes, and returns a result for each statement in order. Each result
+// contains either the rows of a query or the result of an execute.
+// See https://rqlite.io/docs/api/api/#unified-endpoint.
+//
+// To run the queries with a custom consistency level use
+// WithConsistency(level), and to run the statements within a transaction
+// use WithTransaction(true).
+func (g *Gorqlite) Request(sql []string, opts ...RequestOption) (RequestResults, error) {
+	return g.RequestWithContext(context.Background(), sql, opts...)
+}
+
+func (g *Gorqlite) RequestWithContext(ctx context.Context, sql []string, opts ...RequestOption) (RequestResults, error) {
+	return g.RequestStatementsWithContext(ctx, newStatements(sql), opts...)
+}
+
+// RequestStatements runs the given statements, which may include
+// parameters, and returns a result for each statement in order. See
+// Request.
+func (g *Gorqlite) RequestStatements(stmts []Statement, opts ...RequestOption) (RequestResults, error) {
+	return g.RequestStatementsWithContext(context.Background(), stmts, opts...)
+}
+
+func (g *Gorqlite) RequestStatementsWithContext(ctx context.Context, stmts []Statement, opts ...RequestOption) (RequestResults, error) {
+	conf := newRequestConfig(opts...)
+
+	if err := conf.validate(); err != nil {
+		return nil, wrapError(err, "request failed")
+	}
+
+	query := url.Values{}
+	conf.addQuery(query)
+	if conf.Transaction {
+		query.Add("transaction", "")
+	}
+	if conf.Associative {
+		query.Add("associative", "")
+	}
+	if conf.Timings != nil {
+		query.Add("timings", "")
+		ctx = conf.Timings.start(ctx)
+	}
+
+	body, err := json.Marshal(stmts)
+	if err != nil {
+		return nil, wrapError(err, "request failed: failed to marshal query")
+	}
+	resp, err := g.apiClient.PostWithContext(ctx, "/db/request", query, body)
+	if err != nil {
+		return nil, wrapError(err, "request failed: request failed")
+	}
+	defer resp.Body.Close()
+
+	if !isStatusOK(resp.StatusCode) {
+		return nil, wrapError(newStatusError("", resp), "request failed")
+	}
+
+	var requestResp requestResponse
+	if err := json.NewDecoder(resp.Body).Decode(&requestResp); err != nil {
+		return nil, wrapError(err, "request failed: invalid response")
+	}
+	if requestResp.Error != "" {
+		return nil, newError("request failed: %s", requestResp.Error)
+	}
+	if conf.Timings != nil {
+		conf.Timings.Time = secondsToDuration(requestResp.Time)
+	}
+
+	return requestResp.Results, nil
+}
+
 // Status queries the rqlite status API.
 // See https://github.com/rqlite/rqlite/blob/cc74ab0af7c128582b7f0fd380033d43e642a121/DOC/DIAGNOSTICS.md#status-and-diagnostics-api.
 func (g *Gorqlite) Status() (Status, error) {
@@ -186,7 +392,7 @@ func (g *Gorqlite) StatusWithContext(ctx context.Context) (Status, error) {
 	defer resp.Body.Close()
 
 	if !isStatusOK(resp.StatusCode) {
-		return Status{}, newError("failed to fetch status: invalid status code: %d", resp.StatusCode)
+		return Status{}, wrapError(newStatusError("", resp), "failed to fetch status")
 	}
 
 	var status Status
@@ -220,7 +426,7 @@ func (g *Gorqlite) NodesWithContext(ctx context.Context, opts ...NodesOption) (N
 	defer resp.Body.Close()
 
 	if !isStatusOK(resp.StatusCode) {
-		return nil, newError("nodes failed: invalid status code: %d", resp.StatusCode)
+		return nil, wrapError(newStatusError("", resp), "nodes failed")
 	}
 
 	var nodes Nodes
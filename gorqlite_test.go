@@ -1,13 +1,24 @@
 package gorqlite_test
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"math"
+	"math/big"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/dunstall/gorqlite"
 	"github.com/dunstall/gorqlite/mocks/api"
@@ -407,12 +418,13 @@ func TestGorqlite_QueryOK(t *testing.T) {
 	expectedResult := gorqlite.QueryResults{
 		{
 			Columns: []string{"id", "name"},
+			Types:   []string{"integer", "text"},
 			Values: [][]interface{}{
 				{
-					float64(1), "foo",
+					int64(1), "foo",
 				},
 				{
-					float64(2), "bar",
+					int64(2), "bar",
 				},
 			},
 		},
@@ -478,12 +490,13 @@ func TestGorqlite_QueryOneOK(t *testing.T) {
 
 	expectedResult := gorqlite.QueryResult{
 		Columns: []string{"id", "name"},
+		Types:   []string{"integer", "text"},
 		Values: [][]interface{}{
 			{
-				float64(1), "foo",
+				int64(1), "foo",
 			},
 			{
-				float64(2), "bar",
+				int64(2), "bar",
 			},
 		},
 	}
@@ -539,7 +552,7 @@ func TestGorqlite_QueryWithConsistency(t *testing.T) {
 	resp := httpResponse(http.StatusOK, strings.NewReader(body))
 	apiClient := mock_gorqlite.NewMockAPIClient(ctrl)
 	query := url.Values{}
-	query.Add("consistency", "strong")
+	query.Add("level", "strong")
 	apiClient.EXPECT().PostWithContext(
 		gomock.Any(), "/db/query", query, []byte(`["SELECT * FROM mytable"]`),
 	).Return(resp, nil)
@@ -553,12 +566,13 @@ func TestGorqlite_QueryWithConsistency(t *testing.T) {
 
 	expectedResult := gorqlite.QueryResult{
 		Columns: []string{"id", "name"},
+		Types:   []string{"integer", "text"},
 		Values: [][]interface{}{
 			{
-				float64(1), "foo",
+				int64(1), "foo",
 			},
 			{
-				float64(2), "bar",
+				int64(2), "bar",
 			},
 		},
 	}
@@ -583,6 +597,176 @@ func TestGorqlite_QueryWithConsistency(t *testing.T) {
 	require.False(t, ok)
 }
 
+func TestGorqlite_QueryWithFreshness(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	resp := httpResponse(http.StatusOK, strings.NewReader(`{"results": [{"columns": ["id"]}]}`))
+	apiClient := mock_gorqlite.NewMockAPIClient(ctrl)
+	query := url.Values{}
+	query.Add("level", "none")
+	query.Add("freshness", "1s")
+	query.Add("freshness_strict", "")
+	apiClient.EXPECT().PostWithContext(
+		gomock.Any(), "/db/query", query, []byte(`["SELECT * FROM mytable"]`),
+	).Return(resp, nil)
+
+	conn := gorqlite.OpenWithClient(apiClient)
+	_, err := conn.QueryOne(
+		"SELECT * FROM mytable",
+		gorqlite.WithConsistency(gorqlite.ConsistencyNone),
+		gorqlite.WithFreshness(time.Second),
+		gorqlite.WithFreshnessStrict(true),
+	)
+	require.Nil(t, err)
+}
+
+func TestGorqlite_QueryInvalidConsistency(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []gorqlite.QueryOption
+		err  string
+	}{
+		{
+			"unknown level",
+			[]gorqlite.QueryOption{gorqlite.WithConsistency("eventual")},
+			"query failed: invalid consistency level: eventual",
+		},
+		{
+			"freshness without level",
+			[]gorqlite.QueryOption{gorqlite.WithFreshness(time.Second)},
+			"query failed: freshness requires consistency level none",
+		},
+		{
+			"freshness with strong level",
+			[]gorqlite.QueryOption{
+				gorqlite.WithConsistency(gorqlite.ConsistencyStrong),
+				gorqlite.WithFreshness(time.Second),
+			},
+			"query failed: freshness requires consistency level none",
+		},
+		{
+			"negative freshness",
+			[]gorqlite.QueryOption{
+				gorqlite.WithConsistency(gorqlite.ConsistencyNone),
+				gorqlite.WithFreshness(-time.Second),
+			},
+			"query failed: invalid freshness: -1s",
+		},
+		{
+			"strict without freshness",
+			[]gorqlite.QueryOption{
+				gorqlite.WithConsistency(gorqlite.ConsistencyNone),
+				gorqlite.WithFreshnessStrict(true),
+			},
+			"query failed: strict freshness requires freshness",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// No request should be sent.
+			apiClient := mock_gorqlite.NewMockAPIClient(ctrl)
+
+			conn := gorqlite.OpenWithClient(apiClient)
+			_, err := conn.QueryOne("SELECT * FROM mytable", tt.opts...)
+			require.EqualError(t, err, tt.err)
+
+			_, err = conn.QueryStream("SELECT * FROM mytable", tt.opts...)
+			require.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+func TestGorqlite_RequestInvalidConsistency(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// No request should be sent.
+	apiClient := mock_gorqlite.NewMockAPIClient(ctrl)
+
+	conn := gorqlite.OpenWithClient(apiClient)
+	_, err := conn.Request(
+		[]string{"SELECT * FROM mytable"},
+		gorqlite.WithConsistency(gorqlite.ConsistencyWeak),
+		gorqlite.WithFreshness(time.Second),
+	)
+	require.EqualError(t, err, "request failed: freshness requires consistency level none")
+}
+
+func TestGorqlite_QueryWithTimings(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	body := `{
+    "results": [
+        {
+            "columns": ["id", "name"],
+            "types": ["integer", "text"],
+            "values": [[1, "foo"]],
+            "time": 0.0005
+        }
+    ],
+    "time": 0.002
+}`
+	resp := httpResponse(http.StatusOK, strings.NewReader(body))
+	apiClient := mock_gorqlite.NewMockAPIClient(ctrl)
+	query := url.Values{}
+	query.Add("timings", "")
+	apiClient.EXPECT().PostWithContext(
+		gomock.Any(), "/db/query", query, []byte(`["SELECT * FROM mytable"]`),
+	).Return(resp, nil)
+
+	conn := gorqlite.OpenWithClient(apiClient)
+	var timings gorqlite.Timings
+	result, err := conn.QueryOne("SELECT * FROM mytable", gorqlite.WithTimings(&timings))
+	require.Nil(t, err)
+
+	require.Equal(t, 500*time.Microsecond, result.Time)
+	require.Equal(t, 2*time.Millisecond, timings.Time)
+}
+
+func TestGorqlite_QueryAssociative(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	body := `{
+    "results": [
+        {
+            "types": {"id": "integer", "name": "text"},
+            "rows": [
+                {"id": 1, "name": "foo"},
+                {"id": 2, "name": null}
+            ]
+        }
+    ]
+}`
+	resp := httpResponse(http.StatusOK, strings.NewReader(body))
+	apiClient := mock_gorqlite.NewMockAPIClient(ctrl)
+	query := url.Values{}
+	query.Add("associative", "")
+	apiClient.EXPECT().PostWithContext(
+		gomock.Any(), "/db/query", query, []byte(`["SELECT * FROM mytable"]`),
+	).Return(resp, nil)
+
+	conn := gorqlite.OpenWithClient(apiClient)
+	result, err := conn.QueryOne("SELECT * FROM mytable", gorqlite.WithAssociative(true))
+	require.Nil(t, err)
+
+	require.Equal(t, []string{"id", "name"}, result.Columns)
+	require.Equal(t, []string{"integer", "text"}, result.Types)
+	require.Equal(t, [][]interface{}{
+		{int64(1), "foo"},
+		{int64(2), nil},
+	}, result.Values)
+	require.Equal(t, []map[string]interface{}{
+		{"id": int64(1), "name": "foo"},
+		{"id": int64(2), "name": nil},
+	}, result.Maps())
+}
+
 func TestGorqlite_QueryNullResults(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -630,6 +814,7 @@ func TestGorqlite_QueryNullResults(t *testing.T) {
 	expectedResult := gorqlite.QueryResults{
 		{
 			Columns: []string{"id", "name"},
+			Types:   []string{"number", "text"},
 			Values: [][]interface{}{
 				{
 					nil, "foo",
@@ -638,6 +823,7 @@ func TestGorqlite_QueryNullResults(t *testing.T) {
 		},
 		{
 			Columns: []string{"id", "name"},
+			Types:   []string{"number", "text"},
 		},
 	}
 	require.Equal(t, expectedResult, result)
@@ -687,6 +873,187 @@ func TestGorqlite_QueryErrorResults(t *testing.T) {
 	require.Equal(t, "near \"invalid\": syntax error", result.GetFirstError())
 }
 
+func TestGorqlite_QueryStatements(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	body := `{
+    "results": [
+        {
+            "columns": ["id", "name"],
+            "types": ["integer", "text"],
+            "values": [[1, "fiona"]]
+        },
+        {
+            "columns": ["id", "name"],
+            "types": ["integer", "text"],
+            "values": [[2, "sinead"]]
+        }
+    ]
+}`
+	resp := httpResponse(http.StatusOK, strings.NewReader(body))
+	apiClient := mock_gorqlite.NewMockAPIClient(ctrl)
+	apiClient.EXPECT().PostWithContext(
+		gomock.Any(),
+		"/db/query",
+		url.Values{},
+		[]byte(`[["SELECT * FROM foo WHERE name=?","fiona"],["SELECT * FROM foo WHERE name=:name",{"name":"sinead"}]]`),
+	).Return(resp, nil)
+
+	conn := gorqlite.OpenWithClient(apiClient)
+	result, err := conn.QueryStatements([]gorqlite.Statement{
+		gorqlite.NewStatement("SELECT * FROM foo WHERE name=?", "fiona"),
+		gorqlite.NewNamedStatement(
+			"SELECT * FROM foo WHERE name=:name",
+			map[string]interface{}{"name": "sinead"},
+		),
+	})
+	require.Nil(t, err)
+
+	expectedResult := gorqlite.QueryResults{
+		{
+			Columns: []string{"id", "name"},
+			Types:   []string{"integer", "text"},
+			Values:  [][]interface{}{{int64(1), "fiona"}},
+		},
+		{
+			Columns: []string{"id", "name"},
+			Types:   []string{"integer", "text"},
+			Values:  [][]interface{}{{int64(2), "sinead"}},
+		},
+	}
+	require.Equal(t, expectedResult, result)
+}
+
+func TestGorqlite_QueryLargeIntegers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// 9007199254740993 (2^53 + 1) can't be represented exactly as a float64.
+	body := `{
+    "results": [
+        {
+            "columns": ["id", "score", "ratio"],
+            "types": ["integer", "real", ""],
+            "values": [
+                [9007199254740993, 2, 0.5],
+                [9223372036854775807, 1.5, 1e3],
+                [-9223372036854775808, 0, 18446744073709551615]
+            ]
+        }
+    ]
+}`
+	resp := httpResponse(http.StatusOK, strings.NewReader(body))
+	apiClient := mock_gorqlite.NewMockAPIClient(ctrl)
+	apiClient.EXPECT().PostWithContext(
+		gomock.Any(), "/db/query", url.Values{}, []byte(`["SELECT * FROM foo"]`),
+	).Return(resp, nil)
+
+	conn := gorqlite.OpenWithClient(apiClient)
+	result, err := conn.QueryOne("SELECT * FROM foo")
+	require.Nil(t, err)
+
+	require.Equal(t, [][]interface{}{
+		{int64(9007199254740993), float64(2), float64(0.5)},
+		{int64(math.MaxInt64), float64(1.5), float64(1000)},
+		{int64(math.MinInt64), float64(0), float64(18446744073709551615)},
+	}, result.Values)
+
+	var id int64
+	var score float64
+	var ratio float64
+
+	row, ok := result.Next()
+	require.True(t, ok)
+	require.Nil(t, row.Scan(&id, &score, &ratio))
+	require.Equal(t, int64(9007199254740993), id)
+
+	row, ok = result.Next()
+	require.True(t, ok)
+	require.Nil(t, row.Scan(&id, &score, &ratio))
+	require.Equal(t, int64(math.MaxInt64), id)
+
+	var uid uint64
+	require.Nil(t, row.Scan(&uid, &score, &ratio))
+	require.Equal(t, uint64(math.MaxInt64), uid)
+
+	row, ok = result.Next()
+	require.True(t, ok)
+	require.Nil(t, row.Scan(&id, &score, &ratio))
+	require.Equal(t, int64(math.MinInt64), id)
+}
+
+func TestGorqlite_QueryStatementsInvalidParameter(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	apiClient := mock_gorqlite.NewMockAPIClient(ctrl)
+
+	conn := gorqlite.OpenWithClient(apiClient)
+	_, err := conn.QueryStatements([]gorqlite.Statement{
+		gorqlite.NewStatement("SELECT * FROM foo WHERE name=?", struct{}{}),
+	})
+	require.Error(t, err)
+}
+
+func TestGorqlite_QueryStream(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	body := `{
+    "results": [
+        {
+            "columns": ["id", "name"],
+            "types": ["integer", "text"],
+            "values": [[1, "fiona"], [2, "sinead"]]
+        }
+    ]
+}`
+	resp := httpResponse(http.StatusOK, strings.NewReader(body))
+	apiClient := mock_gorqlite.NewMockAPIClient(ctrl)
+	query := url.Values{}
+	query.Add("level", "strong")
+	apiClient.EXPECT().PostWithContext(
+		gomock.Any(), "/db/query", query, []byte(`[["SELECT * FROM foo WHERE id != ?",0]]`),
+	).Return(resp, nil)
+
+	conn := gorqlite.OpenWithClient(apiClient)
+	rows, err := conn.QueryStatementStream(
+		gorqlite.NewStatement("SELECT * FROM foo WHERE id != ?", 0),
+		gorqlite.WithConsistency("strong"),
+	)
+	require.Nil(t, err)
+	defer rows.Close()
+
+	var ids []int64
+	var names []string
+	for rows.Next() {
+		var id int64
+		var name string
+		require.Nil(t, rows.Scan(&id, &name))
+		ids = append(ids, id)
+		names = append(names, name)
+	}
+	require.Nil(t, rows.Err())
+	require.Equal(t, []int64{1, 2}, ids)
+	require.Equal(t, []string{"fiona", "sinead"}, names)
+}
+
+func TestGorqlite_QueryStreamBadStatus(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	resp := httpResponse(http.StatusServiceUnavailable, strings.NewReader(""))
+	apiClient := mock_gorqlite.NewMockAPIClient(ctrl)
+	apiClient.EXPECT().PostWithContext(
+		gomock.Any(), "/db/query", url.Values{}, []byte(`["SELECT * FROM foo"]`),
+	).Return(resp, nil)
+
+	conn := gorqlite.OpenWithClient(apiClient)
+	_, err := conn.QueryStream("SELECT * FROM foo")
+	require.EqualError(t, err, "query failed: bad status code: 503")
+}
+
 func TestGorqlite_QueryBadStatus(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -700,6 +1067,10 @@ func TestGorqlite_QueryBadStatus(t *testing.T) {
 	conn := gorqlite.OpenWithClient(apiClient)
 	_, err := conn.Query([]string{"SELECT ...", "SELECT ..."})
 	require.Error(t, err)
+
+	var statusErr *gorqlite.StatusError
+	require.True(t, errors.As(err, &statusErr))
+	require.Equal(t, http.StatusBadRequest, statusErr.StatusCode)
 }
 
 func TestGorqlite_QueryNetworkError(t *testing.T) {
@@ -815,6 +1186,166 @@ func TestGorqlite_ExecuteWithTransaction(t *testing.T) {
 	require.Equal(t, expectedResult, result)
 }
 
+func TestGorqlite_ExecuteWithQueue(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	body := `{
+    "results": [],
+    "sequence_number": 1653314298877648934
+}`
+	resp := httpResponse(http.StatusOK, strings.NewReader(body))
+	apiClient := mock_gorqlite.NewMockAPIClient(ctrl)
+	query := url.Values{}
+	query.Add("queue", "")
+	apiClient.EXPECT().PostWithContext(
+		gomock.Any(), "/db/execute", query, []byte(`["INSERT ...","INSERT ..."]`),
+	).Return(resp, nil)
+
+	conn := gorqlite.OpenWithClient(apiClient)
+	var queued gorqlite.QueuedWrite
+	results, err := conn.Execute(
+		[]string{"INSERT ...", "INSERT ..."}, gorqlite.WithQueue(&queued),
+	)
+	require.Nil(t, err)
+	require.Equal(t, 0, len(results))
+	require.Equal(t, int64(1653314298877648934), queued.SequenceNumber)
+}
+
+func TestGorqlite_ExecuteOneWithQueueWait(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	body := `{
+    "results": [],
+    "sequence_number": 2
+}`
+	resp := httpResponse(http.StatusOK, strings.NewReader(body))
+	apiClient := mock_gorqlite.NewMockAPIClient(ctrl)
+	query := url.Values{}
+	query.Add("queue", "")
+	query.Add("wait", "")
+	query.Add("timeout", "10s")
+	apiClient.EXPECT().PostWithContext(
+		gomock.Any(), "/db/execute", query, []byte(`["INSERT ..."]`),
+	).Return(resp, nil)
+
+	conn := gorqlite.OpenWithClient(apiClient)
+	var queued gorqlite.QueuedWrite
+	result, err := conn.ExecuteOne(
+		"INSERT ...",
+		gorqlite.WithQueue(&queued),
+		gorqlite.WithQueueWait(true),
+		gorqlite.WithQueueTimeout(10*time.Second),
+	)
+	require.Nil(t, err)
+	require.Equal(t, gorqlite.ExecuteResult{}, result)
+	require.Equal(t, int64(2), queued.SequenceNumber)
+}
+
+func TestGorqlite_ExecuteQueueWaitWithoutQueue(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	resp := httpResponse(http.StatusOK, strings.NewReader(`{"results": [{"rows_affected": 1}]}`))
+	apiClient := mock_gorqlite.NewMockAPIClient(ctrl)
+	// The wait is ignored unless the write is queued.
+	apiClient.EXPECT().PostWithContext(
+		gomock.Any(), "/db/execute", url.Values{}, []byte(`["INSERT ..."]`),
+	).Return(resp, nil)
+
+	conn := gorqlite.OpenWithClient(apiClient)
+	result, err := conn.ExecuteOne("INSERT ...", gorqlite.WithQueueWait(true))
+	require.Nil(t, err)
+	require.Equal(t, gorqlite.ExecuteResult{RowsAffected: 1}, result)
+}
+
+func TestGorqlite_ExecuteQueueTimeoutWithoutWait(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	resp := httpResponse(http.StatusOK, strings.NewReader(`{"sequence_number": 2}`))
+	apiClient := mock_gorqlite.NewMockAPIClient(ctrl)
+	// The timeout is ignored unless waiting for the queue.
+	query := url.Values{}
+	query.Add("queue", "")
+	apiClient.EXPECT().PostWithContext(
+		gomock.Any(), "/db/execute", query, []byte(`["INSERT ..."]`),
+	).Return(resp, nil)
+
+	conn := gorqlite.OpenWithClient(apiClient)
+	var queued gorqlite.QueuedWrite
+	_, err := conn.ExecuteOne(
+		"INSERT ...",
+		gorqlite.WithQueue(&queued),
+		gorqlite.WithQueueTimeout(10*time.Second),
+	)
+	require.Nil(t, err)
+	require.Equal(t, int64(2), queued.SequenceNumber)
+}
+
+func TestGorqlite_ExecuteWithTimings(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	body := `{
+    "results": [
+        {
+            "last_insert_id": 1,
+            "rows_affected": 1,
+            "time": 0.25
+        }
+    ],
+    "time": 1.5
+}`
+	resp := httpResponse(http.StatusOK, strings.NewReader(body))
+	apiClient := mock_gorqlite.NewMockAPIClient(ctrl)
+	query := url.Values{}
+	query.Add("transaction", "")
+	query.Add("timings", "")
+	apiClient.EXPECT().PostWithContext(
+		gomock.Any(), "/db/execute", query, []byte(`["INSERT ..."]`),
+	).Return(resp, nil)
+
+	conn := gorqlite.OpenWithClient(apiClient)
+	var timings gorqlite.Timings
+	result, err := conn.ExecuteOne(
+		"INSERT ...", gorqlite.WithTransaction(true), gorqlite.WithTimings(&timings),
+	)
+	require.Nil(t, err)
+
+	expectedResult := gorqlite.ExecuteResult{
+		LastInsertId: 1,
+		RowsAffected: 1,
+		Time:         250 * time.Millisecond,
+	}
+	require.Equal(t, expectedResult, result)
+	require.Equal(t, 1500*time.Millisecond, timings.Time)
+}
+
+func TestGorqlite_ExecuteWithTimingsAttempts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, ok := r.URL.Query()["timings"]
+		require.True(t, ok)
+		w.Write([]byte(`{"results": [{"rows_affected": 1, "time": 0.001}], "time": 0.002}`))
+	}))
+	defer server.Close()
+
+	conn := gorqlite.Open([]string{server.URL})
+	defer conn.Close()
+
+	var timings gorqlite.Timings
+	_, err := conn.ExecuteOne("INSERT ...", gorqlite.WithTimings(&timings))
+	require.Nil(t, err)
+
+	require.Equal(t, 2*time.Millisecond, timings.Time)
+	require.Equal(t, 1, len(timings.Attempts))
+	require.Equal(t, strings.TrimPrefix(server.URL, "http://"), timings.Attempts[0].Host)
+	require.Equal(t, http.StatusOK, timings.Attempts[0].StatusCode)
+	require.Nil(t, timings.Attempts[0].Err)
+	require.True(t, timings.Attempts[0].Duration > 0)
+}
+
 func TestGorqlite_ExecuteErrorResults(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -853,6 +1384,56 @@ func TestGorqlite_ExecuteErrorResults(t *testing.T) {
 	require.Equal(t, "invalid request", result.GetFirstError())
 }
 
+func TestGorqlite_ExecuteStatements(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	body := `{
+    "results": [
+        {
+            "last_insert_id": 1,
+            "rows_affected": 1
+        },
+        {
+            "last_insert_id": 2,
+            "rows_affected": 1
+        }
+    ]
+}`
+	resp := httpResponse(http.StatusOK, strings.NewReader(body))
+	apiClient := mock_gorqlite.NewMockAPIClient(ctrl)
+	query := url.Values{}
+	query.Add("transaction", "")
+	apiClient.EXPECT().PostWithContext(
+		gomock.Any(),
+		"/db/execute",
+		query,
+		[]byte(`[["INSERT INTO foo(name, age) VALUES(?, ?)","fiona",20],["INSERT INTO foo(name, age) VALUES(:name, :age)",{"age":null,"name":"sinead"}]]`),
+	).Return(resp, nil)
+
+	conn := gorqlite.OpenWithClient(apiClient)
+	result, err := conn.ExecuteStatements([]gorqlite.Statement{
+		gorqlite.NewStatement("INSERT INTO foo(name, age) VALUES(?, ?)", "fiona", 20),
+		gorqlite.NewNamedStatement(
+			"INSERT INTO foo(name, age) VALUES(:name, :age)",
+			map[string]interface{}{"name": "sinead", "age": nil},
+		),
+	}, gorqlite.WithTransaction(true))
+	require.Nil(t, err)
+
+	expectedResult := gorqlite.ExecuteResults{
+		{
+			LastInsertId: 1,
+			RowsAffected: 1,
+		},
+		{
+			LastInsertId: 2,
+			RowsAffected: 1,
+		},
+	}
+	require.Equal(t, expectedResult, result)
+}
+
 func TestGorqlite_ExecuteBadStatus(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -882,6 +1463,171 @@ func TestGorqlite_ExecuteNetworkError(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestGorqlite_RequestOK(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	body := `{
+    "results": [
+        {
+            "last_insert_id": 1,
+            "rows_affected": 1
+        },
+        {
+            "columns": ["id", "name"],
+            "types": ["integer", "text"],
+            "values": [[1, "foo"]]
+        },
+        {
+            "error": "no such table: bar"
+        },
+        {
+            "columns": ["id"],
+            "types": ["integer"]
+        }
+    ]
+}`
+	resp := httpResponse(http.StatusOK, strings.NewReader(body))
+	apiClient := mock_gorqlite.NewMockAPIClient(ctrl)
+	query := url.Values{}
+	query.Add("level", "strong")
+	query.Add("transaction", "")
+	apiClient.EXPECT().PostWithContext(
+		gomock.Any(),
+		"/db/request",
+		query,
+		[]byte(`["INSERT ...","SELECT * FROM foo","SELECT * FROM bar","SELECT id FROM foo WHERE id = 2"]`),
+	).Return(resp, nil)
+
+	conn := gorqlite.OpenWithClient(apiClient)
+	results, err := conn.Request([]string{
+		"INSERT ...",
+		"SELECT * FROM foo",
+		"SELECT * FROM bar",
+		"SELECT id FROM foo WHERE id = 2",
+	}, gorqlite.WithConsistency("strong"), gorqlite.WithTransaction(true))
+	require.Nil(t, err)
+	require.Equal(t, gorqlite.RequestResults{
+		{
+			Execute: &gorqlite.ExecuteResult{LastInsertId: 1, RowsAffected: 1},
+		},
+		{
+			Query: &gorqlite.QueryResult{
+				Columns: []string{"id", "name"},
+				Types:   []string{"integer", "text"},
+				Values:  [][]interface{}{{int64(1), "foo"}},
+			},
+		},
+		{
+			Error: "no such table: bar",
+		},
+		{
+			Query: &gorqlite.QueryResult{
+				Columns: []string{"id"},
+				Types:   []string{"integer"},
+			},
+		},
+	}, results)
+	require.False(t, results[0].IsQuery())
+	require.True(t, results[1].IsQuery())
+	require.Equal(t, "no such table: bar", results.GetFirstError())
+}
+
+func TestGorqlite_RequestWithTimings(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	body := `{
+    "results": [
+        {
+            "rows_affected": 1,
+            "time": 0.0005
+        }
+    ],
+    "time": 0.002
+}`
+	resp := httpResponse(http.StatusOK, strings.NewReader(body))
+	apiClient := mock_gorqlite.NewMockAPIClient(ctrl)
+	query := url.Values{}
+	query.Add("timings", "")
+	apiClient.EXPECT().PostWithContext(
+		gomock.Any(), "/db/request", query, []byte(`["UPDATE ..."]`),
+	).Return(resp, nil)
+
+	conn := gorqlite.OpenWithClient(apiClient)
+	var timings gorqlite.Timings
+	results, err := conn.Request([]string{"UPDATE ..."}, gorqlite.WithTimings(&timings))
+	require.Nil(t, err)
+
+	require.Equal(t, 500*time.Microsecond, results[0].Time)
+	require.Equal(t, 500*time.Microsecond, results[0].Execute.Time)
+	require.Equal(t, 2*time.Millisecond, timings.Time)
+}
+
+func TestGorqlite_RequestStatements(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	resp := httpResponse(http.StatusOK, strings.NewReader(`{"results": [{"last_insert_id": 2, "rows_affected": 1}]}`))
+	apiClient := mock_gorqlite.NewMockAPIClient(ctrl)
+	apiClient.EXPECT().PostWithContext(
+		gomock.Any(), "/db/request", url.Values{}, []byte(`[["INSERT INTO foo(name) VALUES(?)","bar"]]`),
+	).Return(resp, nil)
+
+	conn := gorqlite.OpenWithClient(apiClient)
+	results, err := conn.RequestStatements([]gorqlite.Statement{
+		gorqlite.NewStatement("INSERT INTO foo(name) VALUES(?)", "bar"),
+	})
+	require.Nil(t, err)
+	require.Equal(t, gorqlite.RequestResults{
+		{Execute: &gorqlite.ExecuteResult{LastInsertId: 2, RowsAffected: 1}},
+	}, results)
+}
+
+func TestGorqlite_RequestErrorResponse(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	resp := httpResponse(http.StatusOK, strings.NewReader(`{"error": "invalid request"}`))
+	apiClient := mock_gorqlite.NewMockAPIClient(ctrl)
+	apiClient.EXPECT().PostWithContext(
+		gomock.Any(), "/db/request", url.Values{}, []byte(`["SELECT ..."]`),
+	).Return(resp, nil)
+
+	conn := gorqlite.OpenWithClient(apiClient)
+	_, err := conn.Request([]string{"SELECT ..."})
+	require.EqualError(t, err, "request failed: invalid request")
+}
+
+func TestGorqlite_RequestBadStatus(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	resp := httpResponse(http.StatusBadRequest, strings.NewReader(""))
+	apiClient := mock_gorqlite.NewMockAPIClient(ctrl)
+	apiClient.EXPECT().PostWithContext(
+		gomock.Any(), "/db/request", url.Values{}, []byte(`["SELECT ..."]`),
+	).Return(resp, nil)
+
+	conn := gorqlite.OpenWithClient(apiClient)
+	_, err := conn.Request([]string{"SELECT ..."})
+	require.Error(t, err)
+}
+
+func TestGorqlite_RequestNetworkError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	apiClient := mock_gorqlite.NewMockAPIClient(ctrl)
+	apiClient.EXPECT().PostWithContext(
+		gomock.Any(), "/db/request", url.Values{}, []byte(`["SELECT ..."]`),
+	).Return(nil, fmt.Errorf("network err"))
+
+	conn := gorqlite.OpenWithClient(apiClient)
+	_, err := conn.Request([]string{"SELECT ..."})
+	require.Error(t, err)
+}
+
 func TestGorqlite_StatusOK(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -1054,6 +1800,160 @@ func TestGorqlite_NodesNetworkError(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestGorqlite_OpenHTTPS(t *testing.T) {
+	server := httptest.NewTLSServer(statusHandler())
+	defer server.Close()
+
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(server.Certificate())
+
+	conn := gorqlite.Open([]string{server.URL}, gorqlite.WithRootCAs(rootCAs))
+	status, err := conn.Status()
+	require.Nil(t, err)
+	require.Equal(t, "v6.7.0", status.Build.Version)
+}
+
+func TestGorqlite_OpenHTTPSDefaultScheme(t *testing.T) {
+	server := httptest.NewTLSServer(statusHandler())
+	defer server.Close()
+
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(server.Certificate())
+
+	// Since a TLS option is set the host should default to HTTPS.
+	host := strings.TrimPrefix(server.URL, "https://")
+	conn := gorqlite.Open([]string{host}, gorqlite.WithRootCAs(rootCAs))
+	status, err := conn.Status()
+	require.Nil(t, err)
+	require.Equal(t, "v6.7.0", status.Build.Version)
+}
+
+func TestGorqlite_OpenHTTPSWithTLSConfig(t *testing.T) {
+	server := httptest.NewTLSServer(statusHandler())
+	defer server.Close()
+
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(server.Certificate())
+
+	conn := gorqlite.Open(
+		[]string{server.URL},
+		gorqlite.WithTLSConfig(&tls.Config{
+			RootCAs:    rootCAs,
+			MinVersion: tls.VersionTLS12,
+		}),
+	)
+	status, err := conn.Status()
+	require.Nil(t, err)
+	require.Equal(t, "v6.7.0", status.Build.Version)
+}
+
+func TestGorqlite_OpenHTTPSWithInsecureSkipVerify(t *testing.T) {
+	server := httptest.NewTLSServer(statusHandler())
+	defer server.Close()
+
+	conn := gorqlite.Open(
+		[]string{server.URL}, gorqlite.WithInsecureSkipVerify(true),
+	)
+	status, err := conn.Status()
+	require.Nil(t, err)
+	require.Equal(t, "v6.7.0", status.Build.Version)
+}
+
+func TestGorqlite_OpenMutualTLS(t *testing.T) {
+	clientCert, clientCAs := newClientCertificate(t)
+
+	server := httptest.NewUnstartedServer(statusHandler())
+	server.TLS = &tls.Config{
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  clientCAs,
+		MinVersion: tls.VersionTLS12,
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(server.Certificate())
+
+	conn := gorqlite.Open(
+		[]string{server.URL},
+		gorqlite.WithRootCAs(rootCAs),
+		gorqlite.WithClientCertificate(clientCert),
+	)
+	status, err := conn.Status()
+	require.Nil(t, err)
+	require.Equal(t, "v6.7.0", status.Build.Version)
+}
+
+func TestGorqlite_OpenWithBasicAuth(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		if !ok || username != "alice" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		statusHandler().ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	conn := gorqlite.Open(
+		[]string{server.URL}, gorqlite.WithBasicAuth("alice", "secret"),
+	)
+	status, err := conn.Status()
+	require.Nil(t, err)
+	require.Equal(t, "v6.7.0", status.Build.Version)
+
+	host := strings.TrimPrefix(server.URL, "http://")
+	conn = gorqlite.Open([]string{"alice:secret@" + host})
+	status, err = conn.Status()
+	require.Nil(t, err)
+	require.Equal(t, "v6.7.0", status.Build.Version)
+
+	conn = gorqlite.Open([]string{"alice:wrong@" + host})
+	_, err = conn.Status()
+	require.Error(t, err)
+	require.NotContains(t, err.Error(), "wrong")
+}
+
+// statusHandler returns a handler that responds to /status with a
+// status capture.
+func statusHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, statusV6_7_0JSON)
+	})
+	return mux
+}
+
+// newClientCertificate returns a self-signed client certificate and a pool
+// containing that certificate to verify it.
+func newClientCertificate(t *testing.T) (tls.Certificate, *x509.CertPool) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.Nil(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "gorqlite"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.Nil(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.Nil(t, err)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	return tls.Certificate{
+		Certificate: [][]byte{der},
+		PrivateKey:  key,
+		Leaf:        cert,
+	}, pool
+}
+
 func httpResponse(statusCode int, body io.Reader) *http.Response {
 	return &http.Response{
 		StatusCode: statusCode,
@@ -5,9 +5,13 @@ package gorqlite
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"net/url"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -17,36 +21,186 @@ type roundTripper interface {
 }
 
 type clock interface {
-	Sleep(d time.Duration)
+	// Sleep waits for duration d, or returns the context error if ctx is
+	// done first.
+	Sleep(ctx context.Context, d time.Duration) error
+	Now() time.Time
 }
 
 type systemClock struct{}
 
-func (c *systemClock) Sleep(d time.Duration) {
-	<-time.After(d)
+func (c *systemClock) Now() time.Time {
+	return time.Now()
 }
 
+func (c *systemClock) Sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// httpAPIClient is an APIClient that sends requests to the rqlite HTTP API,
+// load balancing among the known hosts and retrying failed requests.
+//
+// httpAPIClient is safe for concurrent use by multiple goroutines. All
+// mutable state (such as the active host) is guarded by mu.
 type httpAPIClient struct {
-	hosts                []string
-	activeHostIndex      int
+	// seeds are the hosts given to Open, which are always kept in hosts.
+	seeds []apiHost
+	// invalidSeedErr is the error of the last seed that was dropped as
+	// invalid, which is included in ErrNoHosts errors.
+	invalidSeedErr       error
+	defaultScheme        string
 	client               *http.Client
 	clock                clock
 	activeHostRoundRobin bool
+	retryPolicy          RetryPolicy
+	// username and password are the default basic auth credentials, used
+	// for hosts that don't have their own credentials.
+	username string
+	password string
+	hasAuth  bool
+	// random returns a random number in [0, 1) used for jitter.
+	random func() float64
+	// leaderRedirect enables sending requests that must be handled by the
+	// leader directly to the leader.
+	leaderRedirect bool
+
+	mu sync.Mutex
+	// hosts are the seeds followed by any discovered hosts.
+	hosts           []apiHost
+	activeHostIndex int
+	// leader is the cached leader, or nil if the leader is unknown.
+	leader *apiHost
+}
+
+// newHTTPTransport returns the transport to use for the given configuration.
+// This is http.DefaultTransport unless TLS options are set.
+func newHTTPTransport(conf *config) http.RoundTripper {
+	tlsConfig := conf.tls()
+	if tlsConfig == nil {
+		return http.DefaultTransport
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	return transport
+}
+
+// apiHost is the address of a node's HTTP API.
+type apiHost struct {
+	Scheme string
+	Host   string
+	// Username and Password are the basic auth credentials for the host,
+	// only used if HasAuth is set.
+	Username string
+	Password string
+	HasAuth  bool
+}
+
+// parseHost parses an address in format [scheme://][user:pass@]host[:port],
+// using defaultScheme if the address has no scheme.
+//
+// If the address is invalid the returned host has an empty Host, which is
+// rejected by validate.
+func parseHost(addr string, defaultScheme string) apiHost {
+	if !strings.Contains(addr, "://") {
+		addr = defaultScheme + "://" + addr
+	}
+	u, err := url.Parse(addr)
+	if err != nil {
+		// Don't keep the address as it may contain credentials.
+		return apiHost{}
+	}
+
+	host := apiHost{
+		Scheme: u.Scheme,
+		Host:   u.Host,
+	}
+	if u.User != nil {
+		host.Username = u.User.Username()
+		host.Password, _ = u.User.Password()
+		host.HasAuth = true
+	}
+	return host
+}
+
+// noHostsError returns the error when there are no hosts to send a request
+// to, including why any seeds were dropped.
+func (api *httpAPIClient) noHostsError() error {
+	if api.invalidSeedErr != nil {
+		return wrapError(ErrNoHosts, fmt.Sprintf("failed to fetch: %s", api.invalidSeedErr))
+	}
+	return wrapError(ErrNoHosts, "failed to fetch")
+}
+
+// validate checks the host can be used to send requests. Note the returned
+// error must never include the hosts credentials.
+func (h apiHost) validate() error {
+	if h.Host == "" {
+		return newError("invalid host address")
+	}
+	if h.Scheme != "http" && h.Scheme != "https" {
+		return newError("unsupported scheme: %s", h.Scheme)
+	}
+	return nil
 }
 
 func newHTTPAPIClient(hosts []string,
 	transport http.RoundTripper,
 	clock clock,
-	activeHostRoundRobin bool) *httpAPIClient {
+	conf *config) *httpAPIClient {
 	client := &http.Client{
 		Transport: transport,
 	}
+	if conf.LeaderRedirect {
+		// Handle redirects to the leader in fetch rather than following them
+		// in the client, as the client would retry POST requests as GET
+		// without the body.
+		client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		}
+	}
+
+	defaultScheme := "http"
+	if conf.tlsEnabled() {
+		defaultScheme = "https"
+	}
+	apiHosts := make([]apiHost, 0, len(hosts))
+	var invalidSeedErr error
+	for _, addr := range hosts {
+		host := parseHost(addr, defaultScheme)
+		// Drop invalid hosts rather than failing requests that could be
+		// sent to the other hosts.
+		if err := host.validate(); err != nil {
+			invalidSeedErr = err
+			continue
+		}
+		apiHosts = append(apiHosts, host)
+	}
+
 	return &httpAPIClient{
-		hosts:                hosts,
+		seeds:                apiHosts,
+		invalidSeedErr:       invalidSeedErr,
+		defaultScheme:        defaultScheme,
+		hosts:                apiHosts,
 		activeHostIndex:      0,
 		client:               client,
 		clock:                clock,
-		activeHostRoundRobin: activeHostRoundRobin,
+		activeHostRoundRobin: conf.ActiveHostRoundRobin,
+		username:             conf.Username,
+		password:             conf.Password,
+		hasAuth:              conf.HasAuth,
+		retryPolicy:          conf.RetryPolicy,
+		random:               randomFloat64,
+		leaderRedirect:       conf.LeaderRedirect,
+		leader:               nil,
 	}
 }
 
@@ -66,90 +220,550 @@ func (api *httpAPIClient) PostWithContext(ctx context.Context, path string, quer
 	return api.fetch(ctx, http.MethodPost, path, query, body)
 }
 
+func (api *httpAPIClient) DeleteWithContext(ctx context.Context, path string, query url.Values, body []byte) (*http.Response, error) {
+	return api.fetch(ctx, http.MethodDelete, path, query, body)
+}
+
 func (api *httpAPIClient) fetch(ctx context.Context, method, path string, query url.Values, body []byte) (*http.Response, error) {
-	defer api.rotateActiveHost(false)
+	toLeader := requiresLeader(path, query) &&
+		(api.leaderRedirect || alwaysLeaderPaths[path])
+	client := api.client
+	if toLeader {
+		// Copy to avoid modifying the callers query.
+		query = cloneQuery(query)
+		query.Set("redirect", "")
+		client = api.noRedirectClient()
+	}
 
-	var reqBody io.Reader
-	if body != nil {
-		reqBody = bytes.NewReader(body)
+	var attempts []Attempt
+	for {
+		activeHostIndex, activeHost, fromLeader, ok := api.targetHost(toLeader)
+		if !ok {
+			return nil, api.noHostsError()
+		}
+		var reqBody io.Reader
+		if body != nil {
+			reqBody = bytes.NewReader(body)
+		}
+		req, err := api.newRequest(ctx, method, activeHost, path, query, reqBody)
+		if err != nil {
+			return nil, wrapError(err, "failed to fetch")
+		}
+
+		start := api.clock.Now()
+		resp, err := client.Do(req)
+		rtt := api.clock.Now().Sub(start)
+		if err == nil && isStatusOK(resp.StatusCode) {
+			recordAttempt(ctx, Attempt{
+				Host:       activeHost.Host,
+				StatusCode: resp.StatusCode,
+				Err:        nil,
+				Duration:   rtt,
+			})
+			return resp, nil
+		}
+
+		attempt := Attempt{
+			Host:       activeHost.Host,
+			StatusCode: 0,
+			Err:        err,
+			Duration:   rtt,
+		}
+		if err == nil {
+			statusErr := newStatusError(activeHost.Host, resp)
+			attempt.StatusCode = statusErr.StatusCode
+			attempt.Err = statusErr
+		}
+		attempts = append(attempts, attempt)
+		recordAttempt(ctx, attempt)
+
+		// If the node is not the leader it redirects to the leader, so cache
+		// the leader and retry immediately.
+		if err == nil && toLeader && isRedirect(resp.StatusCode) {
+			leader, leaderErr := api.parseLeader(req.URL, resp.Header.Get("Location"))
+			if leaderErr != nil {
+				return nil, wrapError(leaderErr, "failed to fetch: invalid redirect")
+			}
+			if len(attempts) >= api.maxAttempts() {
+				return nil, wrapError(
+					&RetriesExhaustedError{Attempts: attempts},
+					"failed to fetch: too many redirects",
+				)
+			}
+			api.setLeader(leader)
+			continue
+		}
+
+		if err == nil && !api.retryPolicy.retryableStatus(resp.StatusCode) {
+			return nil, wrapError(attempt.Err, "failed to fetch")
+		}
+		if err != nil && !api.retryPolicy.retryableError(err) {
+			return nil, wrapError(err, "failed to fetch")
+		}
+
+		if len(attempts) >= api.maxAttempts() {
+			return nil, wrapError(
+				&RetriesExhaustedError{Attempts: attempts}, "failed to fetch",
+			)
+		}
+
+		backoff := api.retryPolicy.backoff(len(attempts)-1, api.random)
+		if err := api.clock.Sleep(ctx, backoff); err != nil {
+			return nil, wrapError(err, "failed to fetch: cancelled waiting to retry")
+		}
+
+		if fromLeader {
+			// The cached leader may have failed so rediscover the leader.
+			api.clearLeader(activeHost)
+		} else {
+			// Move away from the failed host even if round robin is disabled.
+			api.rotateFailedHost(activeHostIndex)
+		}
 	}
+}
 
-	retryAttempts := 0
-	u := &url.URL{
-		Scheme: "http",
-		// Host set per retry.
-		Host:     "",
-		Path:     path,
-		RawQuery: query.Encode(),
+// PostStreamWithContext sends a POST request with the body read from body
+// rather than buffered in memory, such as to upload a large file.
+//
+// Since the body can only be read once the request is not retried. Though
+// requests that must be handled by the leader are always sent to the leader
+// (rather than forwarded by another node), so if body implements io.Seeker
+// redirects to the leader are followed by rewinding the body to its offset
+// when the request was sent.
+func (api *httpAPIClient) PostStreamWithContext(ctx context.Context, path string, query url.Values, contentType string, body io.Reader) (*http.Response, error) {
+	toLeader := requiresLeader(path, query)
+	if toLeader {
+		// Copy to avoid modifying the callers query.
+		query = cloneQuery(query)
+		query.Set("redirect", "")
 	}
-	req, err := http.NewRequestWithContext(ctx, method, u.String(), reqBody)
-	if err != nil {
-		return nil, wrapError(err, "failed to fetch: invalid request")
+
+	// Always handle redirects, since the client would retry the request as
+	// GET without the body.
+	client := api.noRedirectClient()
+
+	// Record the current offset to rewind to, since the caller may have
+	// already read from body. If the offset can't be read (such as if body
+	// is a pipe) the body can't be rewound.
+	seeker, seekable := body.(io.Seeker)
+	var bodyOffset int64
+	if seekable {
+		offset, err := seeker.Seek(0, io.SeekCurrent)
+		if err != nil {
+			seekable = false
+		}
+		bodyOffset = offset
 	}
 
+	var attempts []Attempt
 	for {
-		activeHost := api.activeHost()
-		if activeHost == "" {
-			return nil, newError("failed to fetch: no addresses given")
+		_, activeHost, fromLeader, ok := api.targetHost(toLeader)
+		if !ok {
+			return nil, api.noHostsError()
+		}
+		req, err := api.newRequest(
+			ctx, http.MethodPost, activeHost, path, query, ioutil.NopCloser(body),
+		)
+		if err != nil {
+			return nil, wrapError(err, "failed to fetch")
+		}
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
 		}
-		req.URL.Host = activeHost
-		req.Host = activeHost
 
-		resp, err := api.client.Do(req)
-		if err == nil && isStatusOK(resp.StatusCode) {
+		start := api.clock.Now()
+		resp, err := client.Do(req)
+		rtt := api.clock.Now().Sub(start)
+		if err != nil {
+			recordAttempt(ctx, Attempt{
+				Host:       activeHost.Host,
+				StatusCode: 0,
+				Err:        err,
+				Duration:   rtt,
+			})
+			if fromLeader {
+				api.clearLeader(activeHost)
+			}
+			return nil, wrapError(err, "failed to fetch")
+		}
+		if isStatusOK(resp.StatusCode) {
+			recordAttempt(ctx, Attempt{
+				Host:       activeHost.Host,
+				StatusCode: resp.StatusCode,
+				Err:        nil,
+				Duration:   rtt,
+			})
 			return resp, nil
 		}
 
-		if err == nil && !isRetryable(resp.StatusCode) {
-			return nil, newError("failed to fetch: bad status code: status: %d", resp.StatusCode)
+		location := resp.Header.Get("Location")
+		statusErr := newStatusError(activeHost.Host, resp)
+		attempt := Attempt{
+			Host:       activeHost.Host,
+			StatusCode: statusErr.StatusCode,
+			Err:        statusErr,
+			Duration:   rtt,
 		}
+		attempts = append(attempts, attempt)
+		recordAttempt(ctx, attempt)
 
-		if retryAttempts >= (len(api.hosts) * 3) {
-			if err != nil {
-				return nil, wrapError(err, "failed to fetch: max retries exceeded")
-			}
-			return nil, newError("failed to fetch: max retries exceeded: status: %d", resp.StatusCode)
+		if !toLeader || !isRedirect(statusErr.StatusCode) {
+			return nil, wrapError(statusErr, "failed to fetch")
+		}
+
+		leader, err := api.parseLeader(req.URL, location)
+		if err != nil {
+			return nil, wrapError(err, "failed to fetch: invalid redirect")
+		}
+		api.setLeader(leader)
+
+		if !seekable || len(attempts) >= api.maxAttempts() {
+			return nil, wrapError(notLeaderError(statusErr), "failed to fetch")
+		}
+		if _, err := seeker.Seek(bodyOffset, io.SeekStart); err != nil {
+			return nil, wrapError(err, "failed to fetch: failed to rewind body")
+		}
+	}
+}
+
+type attemptRecorderKey struct{}
+
+// withAttemptRecorder returns a context that calls record with each attempt
+// made to send a request using the context (including the final successful
+// attempt).
+func withAttemptRecorder(ctx context.Context, record func(attempt Attempt)) context.Context {
+	return context.WithValue(ctx, attemptRecorderKey{}, record)
+}
+
+func recordAttempt(ctx context.Context, attempt Attempt) {
+	if record, ok := ctx.Value(attemptRecorderKey{}).(func(attempt Attempt)); ok {
+		record(attempt)
+	}
+}
+
+func (api *httpAPIClient) maxAttempts() int {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+
+	return api.retryPolicy.maxAttempts(len(api.hosts))
+}
+
+// setDiscoveredHosts replaces the discovered hosts with addrs. The seed
+// hosts are always kept (so hosts are never empty if seeds were given), and
+// any discovered hosts that are not in addrs are removed.
+//
+// Discovered hosts use the default credentials.
+func (api *httpAPIClient) setDiscoveredHosts(addrs []string) {
+	known := make(map[string]bool)
+	hosts := make([]apiHost, 0, len(api.seeds)+len(addrs))
+	for _, seed := range api.seeds {
+		known[seed.Host] = true
+		hosts = append(hosts, seed)
+	}
+	for _, addr := range addrs {
+		host := parseHost(addr, api.defaultScheme)
+		if host.validate() != nil || known[host.Host] {
+			continue
 		}
+		// Never use credentials from the discovered address.
+		host.Username = ""
+		host.Password = ""
+		host.HasAuth = false
+
+		known[host.Host] = true
+		hosts = append(hosts, host)
+	}
+
+	api.mu.Lock()
+	defer api.mu.Unlock()
+
+	api.hosts = hosts
+	if api.activeHostIndex >= len(api.hosts) {
+		api.activeHostIndex = 0
+	}
+}
+
+// currentHosts returns a copy of the hosts requests are sent to.
+func (api *httpAPIClient) currentHosts() []apiHost {
+	api.mu.Lock()
+	defer api.mu.Unlock()
 
-		api.clock.Sleep(waitTimeExponential(retryAttempts, time.Millisecond*100))
+	return append([]apiHost(nil), api.hosts...)
+}
 
-		// Force rotate even if round robin is disabled.
-		api.rotateActiveHost(true)
-		retryAttempts++
+// noRedirectClient returns a client that doesn't follow redirects, so
+// redirects to the leader can be handled by the caller. The client would
+// otherwise retry POST and DELETE requests as GET without the body.
+func (api *httpAPIClient) noRedirectClient() *http.Client {
+	client := *api.client
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
 	}
+	return &client
 }
 
-func (api *httpAPIClient) activeHost() string {
-	if 0 <= api.activeHostIndex && api.activeHostIndex < len(api.hosts) {
-		return api.hosts[api.activeHostIndex]
+// hostAddrs returns the addresses of the known hosts (excluding any
+// credentials).
+func (api *httpAPIClient) hostAddrs() []string {
+	hosts := api.currentHosts()
+	addrs := make([]string, 0, len(hosts))
+	for _, host := range hosts {
+		addrs = append(addrs, host.Host)
 	}
-	return ""
+	return addrs
 }
 
-func (api *httpAPIClient) rotateActiveHost(force bool) {
-	if api.activeHostRoundRobin || force {
-		api.activeHostIndex = ((api.activeHostIndex + 1) % len(api.hosts))
+// activeHostAddr returns the address of the active host, or false if there
+// are no known hosts. Unlike nextHost this doesn't rotate the active host.
+func (api *httpAPIClient) activeHostAddr() (string, bool) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+
+	if len(api.hosts) == 0 {
+		return "", false
+	}
+	return api.hosts[api.activeHostIndex].Host, true
+}
+
+// getFromHost sends a GET request to the known host with address addr. Unlike
+// Get the request is only sent to that host and is not retried.
+func (api *httpAPIClient) getFromHost(ctx context.Context, addr string, path string, query url.Values) (*http.Response, error) {
+	var target apiHost
+	found := false
+	for _, host := range api.currentHosts() {
+		if host.Host == addr {
+			target = host
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, newError("failed to fetch: unknown host: %s", addr)
+	}
+
+	req, err := api.newRequest(ctx, http.MethodGet, target, path, query, nil)
+	if err != nil {
+		return nil, wrapError(err, "failed to fetch")
+	}
+
+	start := api.clock.Now()
+	resp, err := api.client.Do(req)
+	rtt := api.clock.Now().Sub(start)
+	attempt := Attempt{
+		Host:       target.Host,
+		StatusCode: 0,
+		Err:        err,
+		Duration:   rtt,
 	}
+	if err == nil {
+		attempt.StatusCode = resp.StatusCode
+		if !isStatusOK(resp.StatusCode) {
+			attempt.Err = newStatusError(target.Host, resp)
+		}
+	}
+	recordAttempt(ctx, attempt)
+
+	if attempt.Err != nil {
+		return nil, wrapError(attempt.Err, "failed to fetch")
+	}
+	return resp, nil
 }
 
-func isRetryable(statusCode int) bool {
-	retryableCodes := []int{
-		http.StatusRequestTimeout,
-		http.StatusRequestEntityTooLarge,
-		http.StatusTooManyRequests,
-		http.StatusInternalServerError,
-		http.StatusBadGateway,
-		http.StatusServiceUnavailable,
+// newRequest creates a request for the given host. A new request is created
+// for each attempt so the body is reset on retries.
+func (api *httpAPIClient) newRequest(ctx context.Context, method string, host apiHost, path string, query url.Values, body io.Reader) (*http.Request, error) {
+	if err := host.validate(); err != nil {
+		return nil, err
+	}
+
+	u := &url.URL{
+		Scheme:   host.Scheme,
+		Host:     host.Host,
+		Path:     path,
+		RawQuery: query.Encode(),
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, wrapError(err, "invalid request")
+	}
+
+	// Credentials are only added as a header (rather than to the URL) so
+	// they are never included in errors.
+	switch {
+	case host.HasAuth:
+		req.SetBasicAuth(host.Username, host.Password)
+	case api.hasAuth:
+		req.SetBasicAuth(api.username, api.password)
 	}
-	for _, retryableCode := range retryableCodes {
-		if statusCode == retryableCode {
-			return true
+	return req, nil
+}
+
+// targetHost returns the host to send the next request to. If toLeader is
+// set and the leader is cached this returns the leader (with fromLeader
+// set), otherwise it returns the next host as described in nextHost.
+func (api *httpAPIClient) targetHost(toLeader bool) (index int, host apiHost, fromLeader bool, ok bool) {
+	if toLeader {
+		if leader, ok := api.cachedLeader(); ok {
+			return 0, leader, true, true
 		}
 	}
-	return false
+	index, host, ok = api.nextHost()
+	return index, host, false, ok
+}
+
+func (api *httpAPIClient) cachedLeader() (apiHost, bool) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+
+	if api.leader == nil {
+		return apiHost{}, false
+	}
+	return *api.leader, true
+}
+
+func (api *httpAPIClient) setLeader(leader apiHost) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+
+	api.leader = &leader
+}
+
+// clearLeader clears the cached leader if it is still failed (so a leader
+// discovered by another request is not cleared).
+func (api *httpAPIClient) clearLeader(failed apiHost) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+
+	if api.leader != nil && *api.leader == failed {
+		api.leader = nil
+	}
+}
+
+// parseLeader parses the leader from the location of a redirect response to
+// a request to reqURL. If the leader is one of the known hosts, that hosts
+// credentials are used.
+func (api *httpAPIClient) parseLeader(reqURL *url.URL, location string) (apiHost, error) {
+	if location == "" {
+		return apiHost{}, newError("missing location")
+	}
+	u, err := reqURL.Parse(location)
+	if err != nil {
+		return apiHost{}, newError("invalid location")
+	}
+
+	leader := apiHost{
+		Scheme: u.Scheme,
+		Host:   u.Host,
+	}
+	for _, host := range api.currentHosts() {
+		if host.Host == leader.Host {
+			leader.Username = host.Username
+			leader.Password = host.Password
+			leader.HasAuth = host.HasAuth
+			break
+		}
+	}
+	if err := leader.validate(); err != nil {
+		return apiHost{}, err
+	}
+	return leader, nil
+}
+
+// nextHost returns the host to send the next request to, along with its
+// index. If round robin is enabled the active host is rotated so concurrent
+// requests are spread evenly among the hosts.
+func (api *httpAPIClient) nextHost() (int, apiHost, bool) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+
+	if len(api.hosts) == 0 {
+		return 0, apiHost{}, false
+	}
+
+	index := api.activeHostIndex
+	if api.activeHostRoundRobin {
+		api.activeHostIndex = (api.activeHostIndex + 1) % len(api.hosts)
+	}
+	return index, api.hosts[index], true
+}
+
+// rotateFailedHost moves the active host on from the host at index failed.
+// This is a no-op if round robin is enabled (as the active host has already
+// been rotated) or if another request has already rotated away from the
+// failed host.
+func (api *httpAPIClient) rotateFailedHost(failed int) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+
+	if api.activeHostRoundRobin || api.activeHostIndex != failed {
+		return
+	}
+	api.activeHostIndex = (api.activeHostIndex + 1) % len(api.hosts)
+}
+
+// leaderPaths are the API paths that must be handled by the leader.
+var leaderPaths = map[string]bool{
+	"/db/backup":  true,
+	"/db/execute": true,
+	"/db/load":    true,
+	"/db/query":   true,
+	"/db/request": true,
+	"/join":       true,
+	"/remove":     true,
+}
+
+// alwaysLeaderPaths are the API paths that are sent to the leader even if
+// leader redirect is disabled, since rqlite redirects these requests to the
+// leader rather than forwarding them. Handling the redirect in fetch rather
+// than the client also keeps any credentials, which the client drops when
+// redirected to another host.
+var alwaysLeaderPaths = map[string]bool{
+	"/db/backup": true,
+	"/join":      true,
+	"/remove":    true,
+}
+
+// requiresLeader returns true if requests to path must be handled by the
+// leader. Queries with consistency level none and backups with noleader can
+// be handled by any node.
+func requiresLeader(path string, query url.Values) bool {
+	if !leaderPaths[path] {
+		return false
+	}
+	if _, ok := query["noleader"]; ok {
+		return false
+	}
+	return query.Get("level") != "none"
+}
+
+func cloneQuery(query url.Values) url.Values {
+	clone := make(url.Values, len(query))
+	for k, v := range query {
+		clone[k] = append([]string(nil), v...)
+	}
+	return clone
+}
+
+// maxErrorBodySize is the maximum number of bytes of the response body
+// included in a StatusError.
+const maxErrorBodySize = 512
+
+// newStatusError returns an error describing the bad status of resp, and
+// closes the response body.
+func newStatusError(host string, resp *http.Response) *StatusError {
+	defer resp.Body.Close()
+
+	b, _ := ioutil.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+	return &StatusError{
+		Host:       host,
+		StatusCode: resp.StatusCode,
+		Body:       strings.TrimSpace(string(b)),
+	}
 }
 
-func waitTimeExponential(attempt int, base time.Duration) time.Duration {
-	// 2^attempt * base
-	return time.Duration(1<<attempt) * base
+func isRedirect(statusCode int) bool {
+	return statusCode == http.StatusMovedPermanently ||
+		statusCode == http.StatusFound ||
+		statusCode == http.StatusTemporaryRedirect ||
+		statusCode == http.StatusPermanentRedirect
 }
@@ -0,0 +1,101 @@
+package gorqlite
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+type joinRequest struct {
+	ID    string `json:"id"`
+	Addr  string `json:"addr"`
+	Voter bool   `json:"voter"`
+}
+
+// Join adds the node with the given ID and Raft address to the cluster. If
+// voter is false the node joins as a non-voting node, which receives the
+// replicated log but does not take part in elections.
+// See https://github.com/rqlite/rqlite/blob/cc74ab0af7c128582b7f0fd380033d43e642a121/DOC/CLUSTER_MGMT.md.
+//
+// The request is sent to the leader. If there is no leader the error wraps a
+// *NotLeaderError.
+func (g *Gorqlite) Join(ctx context.Context, id string, raftAddr string, voter bool) error {
+	body, err := json.Marshal(joinRequest{
+		ID:    id,
+		Addr:  raftAddr,
+		Voter: voter,
+	})
+	if err != nil {
+		return wrapError(err, "join failed: failed to marshal request")
+	}
+
+	resp, err := g.apiClient.PostWithContext(ctx, "/join", url.Values{}, body)
+	if err != nil {
+		return wrapError(notLeaderError(err), "join failed: request failed")
+	}
+	defer resp.Body.Close()
+
+	if !isStatusOK(resp.StatusCode) {
+		return wrapError(notLeaderError(newStatusError("", resp)), "join failed")
+	}
+	return nil
+}
+
+type removeRequest struct {
+	ID string `json:"id"`
+}
+
+// RemoveNode removes the node with the given ID from the cluster.
+// See https://github.com/rqlite/rqlite/blob/cc74ab0af7c128582b7f0fd380033d43e642a121/DOC/CLUSTER_MGMT.md#removing-or-replacing-a-node.
+//
+// If the node is not a member of the cluster the error wraps
+// ErrUnknownNode. The request is sent to the leader, and if there is no
+// leader the error wraps a *NotLeaderError.
+//
+// A custom API client (see OpenWithClient) must implement DeleteAPIClient to
+// remove nodes.
+func (g *Gorqlite) RemoveNode(ctx context.Context, id string) error {
+	apiClient, ok := g.apiClient.(DeleteAPIClient)
+	if !ok {
+		return newError("remove node failed: api client does not support delete requests")
+	}
+
+	// Check the node is a member of the cluster, since rqlite ignores
+	// removing unknown nodes.
+	nodes, err := g.NodesWithContext(ctx, WithNonVoters(true))
+	if err != nil {
+		return wrapError(err, "remove node failed")
+	}
+	if _, ok := nodes[id]; !ok {
+		return wrapError(ErrUnknownNode, fmt.Sprintf("remove node failed: node %s", id))
+	}
+
+	body, err := json.Marshal(removeRequest{ID: id})
+	if err != nil {
+		return wrapError(err, "remove node failed: failed to marshal request")
+	}
+
+	resp, err := apiClient.DeleteWithContext(ctx, "/remove", url.Values{}, body)
+	if err != nil {
+		return wrapError(removeError(id, err), "remove node failed: request failed")
+	}
+	defer resp.Body.Close()
+
+	if !isStatusOK(resp.StatusCode) {
+		return wrapError(removeError(id, newStatusError("", resp)), "remove node failed")
+	}
+	return nil
+}
+
+// removeError returns an error wrapping ErrUnknownNode if the node was not
+// found, otherwise returns the error as described in notLeaderError.
+func removeError(id string, err error) error {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound {
+		return wrapError(ErrUnknownNode, fmt.Sprintf("node %s", id))
+	}
+	return notLeaderError(err)
+}
@@ -0,0 +1,195 @@
+package gorqlite_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/dunstall/gorqlite"
+	mock_gorqlite "github.com/dunstall/gorqlite/mocks/api"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGorqlite_Join(t *testing.T) {
+	tests := []struct {
+		name  string
+		voter bool
+		body  string
+	}{
+		{"voter", true, `{"id":"4","addr":"node-4:4002","voter":true}`},
+		{"non-voter", false, `{"id":"4","addr":"node-4:4002","voter":false}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			apiClient := mock_gorqlite.NewMockAPIClient(ctrl)
+			apiClient.EXPECT().PostWithContext(
+				gomock.Any(), "/join", url.Values{}, []byte(tt.body),
+			).Return(httpResponse(http.StatusOK, strings.NewReader("")), nil)
+
+			conn := gorqlite.OpenWithClient(apiClient)
+			require.Nil(t, conn.Join(context.Background(), "4", "node-4:4002", tt.voter))
+		})
+	}
+}
+
+func TestGorqlite_JoinNotLeader(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	apiClient := mock_gorqlite.NewMockAPIClient(ctrl)
+	apiClient.EXPECT().PostWithContext(
+		gomock.Any(), "/join", url.Values{}, gomock.Any(),
+	).Return(httpResponse(http.StatusServiceUnavailable, strings.NewReader("leader not found")), nil)
+
+	conn := gorqlite.OpenWithClient(apiClient)
+	err := conn.Join(context.Background(), "4", "node-4:4002", true)
+	var notLeaderErr *gorqlite.NotLeaderError
+	require.True(t, errors.As(err, &notLeaderErr))
+}
+
+func TestGorqlite_JoinBadStatus(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	apiClient := mock_gorqlite.NewMockAPIClient(ctrl)
+	apiClient.EXPECT().PostWithContext(
+		gomock.Any(), "/join", url.Values{}, gomock.Any(),
+	).Return(httpResponse(http.StatusBadRequest, strings.NewReader("")), nil)
+
+	conn := gorqlite.OpenWithClient(apiClient)
+	err := conn.Join(context.Background(), "4", "node-4:4002", true)
+	var statusErr *gorqlite.StatusError
+	require.True(t, errors.As(err, &statusErr))
+	require.Equal(t, http.StatusBadRequest, statusErr.StatusCode)
+	var notLeaderErr *gorqlite.NotLeaderError
+	require.False(t, errors.As(err, &notLeaderErr))
+}
+
+func TestGorqlite_JoinNetworkError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	apiClient := mock_gorqlite.NewMockAPIClient(ctrl)
+	apiClient.EXPECT().PostWithContext(
+		gomock.Any(), "/join", url.Values{}, gomock.Any(),
+	).Return(nil, fmt.Errorf("network err"))
+
+	conn := gorqlite.OpenWithClient(apiClient)
+	require.Error(t, conn.Join(context.Background(), "4", "node-4:4002", true))
+}
+
+func TestGorqlite_RemoveNode(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	query := url.Values{}
+	query.Add("nonvoters", "")
+	apiClient := mock_gorqlite.NewMockAPIClient(ctrl)
+	deleteClient := mock_gorqlite.NewMockDeleteAPIClient(ctrl)
+	gomock.InOrder(
+		apiClient.EXPECT().GetWithContext(
+			gomock.Any(), "/nodes", query,
+		).Return(httpResponse(http.StatusOK, strings.NewReader(nodesV6_7_0JSON)), nil),
+		deleteClient.EXPECT().DeleteWithContext(
+			gomock.Any(), "/remove", url.Values{}, []byte(`{"id":"2"}`),
+		).Return(httpResponse(http.StatusOK, strings.NewReader("")), nil),
+	)
+
+	conn := gorqlite.OpenWithClient(&deleteAPIClient{apiClient, deleteClient})
+	require.Nil(t, conn.RemoveNode(context.Background(), "2"))
+}
+
+func TestGorqlite_RemoveNodeUnknownNode(t *testing.T) {
+	tests := []struct {
+		name  string
+		nodes string
+		resp  *http.Response
+	}{
+		// The node is not in the cluster so is never removed.
+		{"not in nodes", `{}`, nil},
+		// The node was removed after fetching the nodes.
+		{"not found", nodesV6_7_0JSON, httpResponse(http.StatusNotFound, strings.NewReader(""))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			apiClient := mock_gorqlite.NewMockAPIClient(ctrl)
+			deleteClient := mock_gorqlite.NewMockDeleteAPIClient(ctrl)
+			apiClient.EXPECT().GetWithContext(
+				gomock.Any(), "/nodes", gomock.Any(),
+			).Return(httpResponse(http.StatusOK, strings.NewReader(tt.nodes)), nil)
+			if tt.resp != nil {
+				deleteClient.EXPECT().DeleteWithContext(
+					gomock.Any(), "/remove", url.Values{}, gomock.Any(),
+				).Return(tt.resp, nil)
+			}
+
+			conn := gorqlite.OpenWithClient(&deleteAPIClient{apiClient, deleteClient})
+			err := conn.RemoveNode(context.Background(), "2")
+			require.True(t, errors.Is(err, gorqlite.ErrUnknownNode))
+		})
+	}
+}
+
+func TestGorqlite_RemoveNodeNotLeader(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	apiClient := mock_gorqlite.NewMockAPIClient(ctrl)
+	deleteClient := mock_gorqlite.NewMockDeleteAPIClient(ctrl)
+	apiClient.EXPECT().GetWithContext(
+		gomock.Any(), "/nodes", gomock.Any(),
+	).Return(httpResponse(http.StatusOK, strings.NewReader(nodesV6_7_0JSON)), nil)
+	deleteClient.EXPECT().DeleteWithContext(
+		gomock.Any(), "/remove", url.Values{}, gomock.Any(),
+	).Return(httpResponse(http.StatusServiceUnavailable, strings.NewReader("not leader")), nil)
+
+	conn := gorqlite.OpenWithClient(&deleteAPIClient{apiClient, deleteClient})
+	err := conn.RemoveNode(context.Background(), "2")
+	var notLeaderErr *gorqlite.NotLeaderError
+	require.True(t, errors.As(err, &notLeaderErr))
+	require.False(t, errors.Is(err, gorqlite.ErrUnknownNode))
+}
+
+func TestGorqlite_RemoveNodeNetworkError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	apiClient := mock_gorqlite.NewMockAPIClient(ctrl)
+	deleteClient := mock_gorqlite.NewMockDeleteAPIClient(ctrl)
+	apiClient.EXPECT().GetWithContext(
+		gomock.Any(), "/nodes", gomock.Any(),
+	).Return(nil, fmt.Errorf("network err"))
+
+	conn := gorqlite.OpenWithClient(&deleteAPIClient{apiClient, deleteClient})
+	require.Error(t, conn.RemoveNode(context.Background(), "2"))
+}
+
+func TestGorqlite_RemoveNodeUnsupportedClient(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// The client doesn't implement DeleteAPIClient.
+	apiClient := mock_gorqlite.NewMockAPIClient(ctrl)
+
+	conn := gorqlite.OpenWithClient(apiClient)
+	err := conn.RemoveNode(context.Background(), "2")
+	require.EqualError(t, err, "remove node failed: api client does not support delete requests")
+}
+
+// deleteAPIClient is a mock API client that also implements
+// gorqlite.DeleteAPIClient.
+type deleteAPIClient struct {
+	*mock_gorqlite.MockAPIClient
+	*mock_gorqlite.MockDeleteAPIClient
+}
@@ -6,6 +6,7 @@ package mock_gorqlite
 
 import (
 	context "context"
+	io "io"
 	http "net/http"
 	url "net/url"
 	reflect "reflect"
@@ -95,3 +96,79 @@ func (mr *MockAPIClientMockRecorder) PostWithContext(ctx, path, query, body inte
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostWithContext", reflect.TypeOf((*MockAPIClient)(nil).PostWithContext), ctx, path, query, body)
 }
+
+// MockStreamAPIClient is a mock of StreamAPIClient interface.
+type MockStreamAPIClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockStreamAPIClientMockRecorder
+}
+
+// MockStreamAPIClientMockRecorder is the mock recorder for MockStreamAPIClient.
+type MockStreamAPIClientMockRecorder struct {
+	mock *MockStreamAPIClient
+}
+
+// NewMockStreamAPIClient creates a new mock instance.
+func NewMockStreamAPIClient(ctrl *gomock.Controller) *MockStreamAPIClient {
+	mock := &MockStreamAPIClient{ctrl: ctrl}
+	mock.recorder = &MockStreamAPIClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStreamAPIClient) EXPECT() *MockStreamAPIClientMockRecorder {
+	return m.recorder
+}
+
+// PostStreamWithContext mocks base method.
+func (m *MockStreamAPIClient) PostStreamWithContext(ctx context.Context, path string, query url.Values, contentType string, body io.Reader) (*http.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PostStreamWithContext", ctx, path, query, contentType, body)
+	ret0, _ := ret[0].(*http.Response)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PostStreamWithContext indicates an expected call of PostStreamWithContext.
+func (mr *MockStreamAPIClientMockRecorder) PostStreamWithContext(ctx, path, query, contentType, body interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostStreamWithContext", reflect.TypeOf((*MockStreamAPIClient)(nil).PostStreamWithContext), ctx, path, query, contentType, body)
+}
+
+// MockDeleteAPIClient is a mock of DeleteAPIClient interface.
+type MockDeleteAPIClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockDeleteAPIClientMockRecorder
+}
+
+// MockDeleteAPIClientMockRecorder is the mock recorder for MockDeleteAPIClient.
+type MockDeleteAPIClientMockRecorder struct {
+	mock *MockDeleteAPIClient
+}
+
+// NewMockDeleteAPIClient creates a new mock instance.
+func NewMockDeleteAPIClient(ctrl *gomock.Controller) *MockDeleteAPIClient {
+	mock := &MockDeleteAPIClient{ctrl: ctrl}
+	mock.recorder = &MockDeleteAPIClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDeleteAPIClient) EXPECT() *MockDeleteAPIClientMockRecorder {
+	return m.recorder
+}
+
+// DeleteWithContext mocks base method.
+func (m *MockDeleteAPIClient) DeleteWithContext(ctx context.Context, path string, query url.Values, body []byte) (*http.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWithContext", ctx, path, query, body)
+	ret0, _ := ret[0].(*http.Response)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteWithContext indicates an expected call of DeleteWithContext.
+func (mr *MockDeleteAPIClientMockRecorder) DeleteWithContext(ctx, path, query, body interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWithContext", reflect.TypeOf((*MockDeleteAPIClient)(nil).DeleteWithContext), ctx, path, query, body)
+}
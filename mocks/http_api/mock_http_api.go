@@ -5,6 +5,7 @@
 package mock_gorqlite
 
 import (
+	context "context"
 	http "net/http"
 	reflect "reflect"
 	time "time"
@@ -73,14 +74,30 @@ func (m *Mockclock) EXPECT() *MockclockMockRecorder {
 	return m.recorder
 }
 
+// Now mocks base method.
+func (m *Mockclock) Now() time.Time {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Now")
+	ret0, _ := ret[0].(time.Time)
+	return ret0
+}
+
+// Now indicates an expected call of Now.
+func (mr *MockclockMockRecorder) Now() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Now", reflect.TypeOf((*Mockclock)(nil).Now))
+}
+
 // Sleep mocks base method.
-func (m *Mockclock) Sleep(d time.Duration) {
+func (m *Mockclock) Sleep(ctx context.Context, d time.Duration) error {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "Sleep", d)
+	ret := m.ctrl.Call(m, "Sleep", ctx, d)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
 // Sleep indicates an expected call of Sleep.
-func (mr *MockclockMockRecorder) Sleep(d interface{}) *gomock.Call {
+func (mr *MockclockMockRecorder) Sleep(ctx, d interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Sleep", reflect.TypeOf((*Mockclock)(nil).Sleep), d)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Sleep", reflect.TypeOf((*Mockclock)(nil).Sleep), ctx, d)
 }
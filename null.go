@@ -0,0 +1,155 @@
+package gorqlite
+
+import (
+	"database/sql/driver"
+	"time"
+)
+
+// NullString is a string that may be NULL. It can be used as a Scan
+// destination to tell NULL columns apart from empty strings, and as a
+// statement parameter.
+type NullString struct {
+	String string
+	// Valid is true if String is not NULL.
+	Valid bool
+}
+
+// Scan implements the sql.Scanner interface.
+func (n *NullString) Scan(value interface{}) error {
+	if value == nil {
+		n.String, n.Valid = "", false
+		return nil
+	}
+	if err := scanValue(value, &n.String); err != nil {
+		return err
+	}
+	n.Valid = true
+	return nil
+}
+
+// Value implements the driver.Valuer interface.
+func (n NullString) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+	return n.String, nil
+}
+
+// NullInt64 is an int64 that may be NULL. It can be used as a Scan
+// destination to tell NULL columns apart from zero, and as a statement
+// parameter.
+type NullInt64 struct {
+	Int64 int64
+	// Valid is true if Int64 is not NULL.
+	Valid bool
+}
+
+// Scan implements the sql.Scanner interface.
+func (n *NullInt64) Scan(value interface{}) error {
+	if value == nil {
+		n.Int64, n.Valid = 0, false
+		return nil
+	}
+	if err := scanValue(value, &n.Int64); err != nil {
+		return err
+	}
+	n.Valid = true
+	return nil
+}
+
+// Value implements the driver.Valuer interface.
+func (n NullInt64) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+	return n.Int64, nil
+}
+
+// NullFloat64 is a float64 that may be NULL. It can be used as a Scan
+// destination to tell NULL columns apart from zero, and as a statement
+// parameter.
+type NullFloat64 struct {
+	Float64 float64
+	// Valid is true if Float64 is not NULL.
+	Valid bool
+}
+
+// Scan implements the sql.Scanner interface.
+func (n *NullFloat64) Scan(value interface{}) error {
+	if value == nil {
+		n.Float64, n.Valid = 0, false
+		return nil
+	}
+	if err := scanValue(value, &n.Float64); err != nil {
+		return err
+	}
+	n.Valid = true
+	return nil
+}
+
+// Value implements the driver.Valuer interface.
+func (n NullFloat64) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+	return n.Float64, nil
+}
+
+// NullBool is a bool that may be NULL. It can be used as a Scan destination
+// to tell NULL columns apart from false, and as a statement parameter.
+type NullBool struct {
+	Bool bool
+	// Valid is true if Bool is not NULL.
+	Valid bool
+}
+
+// Scan implements the sql.Scanner interface.
+func (n *NullBool) Scan(value interface{}) error {
+	if value == nil {
+		n.Bool, n.Valid = false, false
+		return nil
+	}
+	if err := scanValue(value, &n.Bool); err != nil {
+		return err
+	}
+	n.Valid = true
+	return nil
+}
+
+// Value implements the driver.Valuer interface.
+func (n NullBool) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+	return n.Bool, nil
+}
+
+// NullTime is a time.Time that may be NULL. It can be used as a Scan
+// destination to tell NULL columns apart from the zero time, and as a
+// statement parameter.
+type NullTime struct {
+	Time time.Time
+	// Valid is true if Time is not NULL.
+	Valid bool
+}
+
+// Scan implements the sql.Scanner interface.
+func (n *NullTime) Scan(value interface{}) error {
+	if value == nil {
+		n.Time, n.Valid = time.Time{}, false
+		return nil
+	}
+	if err := scanValue(value, &n.Time); err != nil {
+		return err
+	}
+	n.Valid = true
+	return nil
+}
+
+// Value implements the driver.Valuer interface.
+func (n NullTime) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+	return n.Time, nil
+}
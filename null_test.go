@@ -0,0 +1,85 @@
+package gorqlite
+
+import (
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNullString(t *testing.T) {
+	var n NullString
+	require.Nil(t, n.Scan("foo"))
+	require.Equal(t, NullString{String: "foo", Valid: true}, n)
+	v, err := n.Value()
+	require.Nil(t, err)
+	require.Equal(t, driver.Value("foo"), v)
+
+	require.Nil(t, n.Scan(nil))
+	require.Equal(t, NullString{}, n)
+	v, err = n.Value()
+	require.Nil(t, err)
+	require.Nil(t, v)
+}
+
+func TestNullInt64(t *testing.T) {
+	var n NullInt64
+	require.Nil(t, n.Scan(float64(10)))
+	require.Equal(t, NullInt64{Int64: 10, Valid: true}, n)
+	v, err := n.Value()
+	require.Nil(t, err)
+	require.Equal(t, driver.Value(int64(10)), v)
+
+	require.Nil(t, n.Scan(nil))
+	require.Equal(t, NullInt64{}, n)
+	v, err = n.Value()
+	require.Nil(t, err)
+	require.Nil(t, v)
+}
+
+func TestNullFloat64(t *testing.T) {
+	var n NullFloat64
+	require.Nil(t, n.Scan(float64(1.5)))
+	require.Equal(t, NullFloat64{Float64: 1.5, Valid: true}, n)
+	v, err := n.Value()
+	require.Nil(t, err)
+	require.Equal(t, driver.Value(1.5), v)
+
+	require.Nil(t, n.Scan(nil))
+	require.Equal(t, NullFloat64{}, n)
+}
+
+func TestNullBool(t *testing.T) {
+	var n NullBool
+	require.Nil(t, n.Scan(int64(1)))
+	require.Equal(t, NullBool{Bool: true, Valid: true}, n)
+	v, err := n.Value()
+	require.Nil(t, err)
+	require.Equal(t, driver.Value(true), v)
+
+	require.Nil(t, n.Scan(nil))
+	require.Equal(t, NullBool{}, n)
+}
+
+func TestNullTime(t *testing.T) {
+	var n NullTime
+	require.Nil(t, n.Scan("2022-01-01T20:10:51Z"))
+	expected := time.Date(2022, time.January, 1, 20, 10, 51, 0, time.UTC)
+	require.Equal(t, NullTime{Time: expected, Valid: true}, n)
+	v, err := n.Value()
+	require.Nil(t, err)
+	require.Equal(t, driver.Value(expected), v)
+
+	require.Nil(t, n.Scan(nil))
+	require.Equal(t, NullTime{}, n)
+}
+
+func TestNull_EncodeParameter(t *testing.T) {
+	stmt := NewStatement(
+		"INSERT INTO foo VALUES(?, ?)", NullString{String: "foo", Valid: true}, NullInt64{},
+	)
+	b, err := stmt.MarshalJSON()
+	require.Nil(t, err)
+	require.Equal(t, `["INSERT INTO foo VALUES(?, ?)","foo",null]`, string(b))
+}
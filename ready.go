@@ -0,0 +1,129 @@
+package gorqlite
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// hostsAPIClient is implemented by API clients that can send requests to a
+// specific host, which is used to check whether each host is ready.
+type hostsAPIClient interface {
+	hostAddrs() []string
+	activeHostAddr() (string, bool)
+	getFromHost(ctx context.Context, addr string, path string, query url.Values) (*http.Response, error)
+}
+
+// Ready checks whether the active node is ready to handle requests, returning
+// nil if the node is ready. If the node is not ready the error wraps a
+// *NotReadyError describing why.
+// See https://github.com/rqlite/rqlite/blob/cc74ab0af7c128582b7f0fd380033d43e642a121/DOC/DIAGNOSTICS.md#readiness-checks.
+//
+// Unlike other requests the check is sent once and is not retried on the
+// other known nodes. Use WaitUntilReady to check every node.
+//
+// If the client cannot send requests to a specific host (such as a custom
+// API client) the check is sent with GetWithContext.
+func (g *Gorqlite) Ready(ctx context.Context, opts ...ReadyOption) error {
+	conf := newReadyConfig(opts...)
+
+	apiClient, ok := g.apiClient.(hostsAPIClient)
+	if !ok {
+		return g.ready(ctx, conf)
+	}
+	addr, ok := apiClient.activeHostAddr()
+	if !ok {
+		return wrapError(ErrNoHosts, "ready failed")
+	}
+	if err := readyHost(ctx, apiClient, addr, conf); err != nil {
+		return wrapError(err, "ready failed")
+	}
+	return nil
+}
+
+func (g *Gorqlite) ready(ctx context.Context, conf *readyConfig) error {
+	resp, err := g.apiClient.GetWithContext(ctx, "/readyz", conf.query())
+	if err != nil {
+		return wrapError(notReadyError(err), "ready failed")
+	}
+	defer resp.Body.Close()
+
+	if !isStatusOK(resp.StatusCode) {
+		return wrapError(notReadyError(newStatusError("", resp)), "ready failed")
+	}
+	return nil
+}
+
+// readyHost checks whether the host with address addr is ready. The check is
+// only sent to that host and is not retried.
+func readyHost(ctx context.Context, apiClient hostsAPIClient, addr string, conf *readyConfig) error {
+	resp, err := apiClient.getFromHost(ctx, addr, "/readyz", conf.query())
+	if err != nil {
+		return notReadyError(err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// WaitUntilReady waits until every known host is ready to handle requests
+// (see Ready), or until a quorum of hosts are ready when using
+// WithReadyQuorum. The hosts are checked every poll interval (see
+// WithReadyPollInterval) until they are ready or ctx is done.
+//
+// If the client cannot send requests to a specific host (such as a custom
+// API client) this waits until Ready succeeds.
+func (g *Gorqlite) WaitUntilReady(ctx context.Context, opts ...ReadyOption) error {
+	conf := newReadyConfig(opts...)
+
+	for {
+		ready, total, err := g.readyHosts(ctx, conf)
+		if total == 0 {
+			// With no hosts there is nothing to wait for.
+			return wrapError(ErrNoHosts, "wait until ready failed")
+		}
+		required := total
+		if conf.Quorum {
+			required = total/2 + 1
+		}
+		if ready >= required {
+			return nil
+		}
+
+		timer := time.NewTimer(conf.PollInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return wrapError(ctx.Err(), fmt.Sprintf(
+				"wait until ready failed: %d of %d hosts ready (last error: %s)",
+				ready, total, err,
+			))
+		case <-timer.C:
+		}
+	}
+}
+
+// readyHosts returns the number of ready hosts, the number of hosts checked
+// and the last error if any hosts are not ready.
+func (g *Gorqlite) readyHosts(ctx context.Context, conf *readyConfig) (int, int, error) {
+	apiClient, ok := g.apiClient.(hostsAPIClient)
+	if !ok {
+		if err := g.ready(ctx, conf); err != nil {
+			return 0, 1, err
+		}
+		return 1, 1, nil
+	}
+
+	addrs := apiClient.hostAddrs()
+	ready := 0
+	var lastErr error
+	for _, addr := range addrs {
+		if err := readyHost(ctx, apiClient, addr, conf); err != nil {
+			lastErr = err
+			continue
+		}
+		ready++
+	}
+	return ready, len(addrs), lastErr
+}
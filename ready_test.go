@@ -0,0 +1,200 @@
+package gorqlite
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	mock_api "github.com/dunstall/gorqlite/mocks/api"
+	mock_gorqlite "github.com/dunstall/gorqlite/mocks/http_api"
+	gomock "github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGorqlite_Ready(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	transport := mock_gorqlite.NewMockroundTripper(ctrl)
+	api := newHTTPAPIClient([]string{"rqlite-0"}, transport, &nopClock{}, newConfig())
+	g := &Gorqlite{apiClient: api, discovery: nil}
+
+	gomock.InOrder(
+		transport.EXPECT().RoundTrip(
+			newHTTPReqBodyMatcher(http.MethodGet, "http://rqlite-0/readyz", nil),
+		).Return(httpResponse(http.StatusOK, strings.NewReader("[+]node ok")), nil),
+		transport.EXPECT().RoundTrip(
+			newHTTPReqBodyMatcher(http.MethodGet, "http://rqlite-0/readyz?noleader=&sync=&timeout=2s", nil),
+		).Return(httpResponse(http.StatusOK, strings.NewReader("[+]node ok")), nil),
+	)
+
+	require.Nil(t, g.Ready(context.Background()))
+	require.Nil(t, g.Ready(
+		context.Background(),
+		WithReadyNoLeader(true),
+		WithReadySync(true, 2*time.Second),
+	))
+}
+
+func TestGorqlite_ReadyNotReady(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	addrs := []string{"rqlite-0", "rqlite-1", "rqlite-2"}
+	transport := mock_gorqlite.NewMockroundTripper(ctrl)
+	api := newHTTPAPIClient(addrs, transport, &nopClock{}, newConfig())
+	g := &Gorqlite{apiClient: api, discovery: nil}
+
+	// Only the active host is checked, without retrying on the other hosts.
+	transport.EXPECT().RoundTrip(
+		newHTTPReqBodyMatcher(http.MethodGet, "http://rqlite-0/readyz", nil),
+	).Return(httpResponse(
+		http.StatusServiceUnavailable, strings.NewReader("[+]node ok\n[-]leader not ok\n"),
+	), nil)
+
+	err := g.Ready(context.Background())
+	var notReadyErr *NotReadyError
+	require.True(t, errors.As(err, &notReadyErr))
+	require.Equal(t, "rqlite-0", notReadyErr.Host)
+	require.Equal(t, "[+]node ok\n[-]leader not ok", notReadyErr.Reason)
+}
+
+func TestGorqlite_ReadyNoHosts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	transport := mock_gorqlite.NewMockroundTripper(ctrl)
+	api := newHTTPAPIClient([]string{}, transport, &nopClock{}, newConfig())
+	g := &Gorqlite{apiClient: api, discovery: nil}
+
+	err := g.Ready(context.Background())
+	require.True(t, errors.Is(err, ErrNoHosts))
+}
+
+func TestGorqlite_WaitUntilReady(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	addrs := []string{"rqlite-0", "rqlite-1", "rqlite-2"}
+	transport := mock_gorqlite.NewMockroundTripper(ctrl)
+	api := newHTTPAPIClient(addrs, transport, &nopClock{}, newConfig())
+	g := &Gorqlite{apiClient: api, discovery: nil}
+
+	gomock.InOrder(
+		// rqlite-1 is not ready so all hosts are checked again.
+		transport.EXPECT().RoundTrip(
+			newHTTPReqBodyMatcher(http.MethodGet, "http://rqlite-0/readyz", nil),
+		).Return(httpResponse(http.StatusOK, strings.NewReader("")), nil),
+		transport.EXPECT().RoundTrip(
+			newHTTPReqBodyMatcher(http.MethodGet, "http://rqlite-1/readyz", nil),
+		).Return(httpResponse(http.StatusServiceUnavailable, strings.NewReader("")), nil),
+		transport.EXPECT().RoundTrip(
+			newHTTPReqBodyMatcher(http.MethodGet, "http://rqlite-2/readyz", nil),
+		).Return(httpResponse(http.StatusOK, strings.NewReader("")), nil),
+		transport.EXPECT().RoundTrip(
+			newHTTPReqBodyMatcher(http.MethodGet, "http://rqlite-0/readyz", nil),
+		).Return(httpResponse(http.StatusOK, strings.NewReader("")), nil),
+		transport.EXPECT().RoundTrip(
+			newHTTPReqBodyMatcher(http.MethodGet, "http://rqlite-1/readyz", nil),
+		).Return(httpResponse(http.StatusOK, strings.NewReader("")), nil),
+		transport.EXPECT().RoundTrip(
+			newHTTPReqBodyMatcher(http.MethodGet, "http://rqlite-2/readyz", nil),
+		).Return(httpResponse(http.StatusOK, strings.NewReader("")), nil),
+	)
+
+	require.Nil(t, g.WaitUntilReady(context.Background(), WithReadyPollInterval(time.Millisecond)))
+}
+
+func TestGorqlite_WaitUntilReadyQuorum(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	addrs := []string{"rqlite-0", "rqlite-1", "rqlite-2"}
+	transport := mock_gorqlite.NewMockroundTripper(ctrl)
+	api := newHTTPAPIClient(addrs, transport, &nopClock{}, newConfig())
+	g := &Gorqlite{apiClient: api, discovery: nil}
+
+	gomock.InOrder(
+		transport.EXPECT().RoundTrip(
+			newHTTPReqBodyMatcher(http.MethodGet, "http://rqlite-0/readyz?noleader=", nil),
+		).Return(httpResponse(http.StatusOK, strings.NewReader("")), nil),
+		transport.EXPECT().RoundTrip(
+			newHTTPReqBodyMatcher(http.MethodGet, "http://rqlite-1/readyz?noleader=", nil),
+		).Return(nil, errors.New("connection refused")),
+		transport.EXPECT().RoundTrip(
+			newHTTPReqBodyMatcher(http.MethodGet, "http://rqlite-2/readyz?noleader=", nil),
+		).Return(httpResponse(http.StatusOK, strings.NewReader("")), nil),
+	)
+
+	// Two of three hosts is a quorum.
+	require.Nil(t, g.WaitUntilReady(
+		context.Background(), WithReadyQuorum(true), WithReadyNoLeader(true),
+	))
+}
+
+func TestGorqlite_WaitUntilReadyCancelled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	addrs := []string{"rqlite-0", "rqlite-1"}
+	transport := mock_gorqlite.NewMockroundTripper(ctrl)
+	api := newHTTPAPIClient(addrs, transport, &nopClock{}, newConfig())
+	g := &Gorqlite{apiClient: api, discovery: nil}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	gomock.InOrder(
+		transport.EXPECT().RoundTrip(
+			newHTTPReqBodyMatcher(http.MethodGet, "http://rqlite-0/readyz", nil),
+		).Return(httpResponse(http.StatusOK, strings.NewReader("")), nil),
+		transport.EXPECT().RoundTrip(
+			newHTTPReqBodyMatcher(http.MethodGet, "http://rqlite-1/readyz", nil),
+		).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+			cancel()
+			return httpResponse(http.StatusServiceUnavailable, strings.NewReader("[-]leader not ok")), nil
+		}),
+	)
+
+	err := g.WaitUntilReady(ctx, WithReadyPollInterval(time.Hour))
+	require.True(t, errors.Is(err, context.Canceled))
+	require.EqualError(
+		t, err,
+		"wait until ready failed: 1 of 2 hosts ready (last error: not ready: host rqlite-1: [-]leader not ok): context canceled",
+	)
+}
+
+func TestGorqlite_WaitUntilReadyNoHosts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	transport := mock_gorqlite.NewMockroundTripper(ctrl)
+	api := newHTTPAPIClient([]string{}, transport, &nopClock{}, newConfig())
+	g := &Gorqlite{apiClient: api, discovery: nil}
+
+	// Returns immediately rather than waiting for the context to be done.
+	err := g.WaitUntilReady(context.Background(), WithReadyPollInterval(time.Hour))
+	require.True(t, errors.Is(err, ErrNoHosts))
+}
+
+func TestGorqlite_WaitUntilReadyCustomClient(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	apiClient := mock_api.NewMockAPIClient(ctrl)
+	gomock.InOrder(
+		apiClient.EXPECT().GetWithContext(gomock.Any(), "/readyz", url.Values{}).Return(
+			httpResponse(http.StatusServiceUnavailable, strings.NewReader("")), nil,
+		),
+		apiClient.EXPECT().GetWithContext(gomock.Any(), "/readyz", url.Values{}).Return(
+			httpResponse(http.StatusOK, strings.NewReader("")), nil,
+		),
+	)
+
+	g := OpenWithClient(apiClient)
+	require.Nil(t, g.WaitUntilReady(context.Background(), WithReadyPollInterval(time.Millisecond)))
+}
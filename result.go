@@ -1,15 +1,47 @@
 package gorqlite
 
 import (
-	"strconv"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
 	"time"
 )
 
+// QueryRow is a row in a query result. Types contains the declared type of
+// each column (such as integer, text or blob), which is empty for columns
+// without a declared type (such as expressions).
 type QueryRow struct {
 	Columns []string
+	Types   []string
 	Values  []interface{}
 }
 
+// Scan copies the columns in the row into the values pointed to by vars.
+//
+// Supported destinations are:
+//   - *string and *[]byte
+//   - *bool
+//   - signed and unsigned integers (such as *int, *int32 and *uint64)
+//   - *float32 and *float64
+//   - *time.Time
+//   - *interface{}, which is set to the value as decoded from JSON
+//   - sql.Scanner, such as NullString and NullInt64
+//   - a pointer to a pointer to any of the above, which is set to nil if the
+//     column is NULL
+//
+// Other destinations are set to their zero value if the column is NULL, so
+// use a pointer or a Null type (such as NullString) to tell NULL apart from
+// the zero value.
+//
+// The declared column types in Types are used to decode values, so blob
+// columns (which rqlite encodes as base64) are decoded to bytes, and only
+// datetime columns containing numbers can be scanned into numeric
+// destinations. Strings are parsed as times if they are in RFC 3339 format
+// or any of the formats used by SQLite date and time functions.
+//
 // Based on https://github.com/rqlite/gorqlite/blob/5cf06496fee7a89243002b194bd095eba64346b3/query.go#L385.
 func (r *QueryRow) Scan(vars ...interface{}) error {
 	if len(r.Columns) != len(r.Values) {
@@ -26,84 +58,356 @@ func (r *QueryRow) Scan(vars ...interface{}) error {
 	}
 
 	for i, dest := range vars {
-		src := r.Values[i]
-		// Skip nil items.
-		if src == nil {
-			continue
+		if err := scanTypedValue(r.Values[i], r.columnType(i), dest); err != nil {
+			return err
 		}
+	}
 
-		switch dest.(type) {
-		case *string:
-			switch src := src.(type) {
-			case string:
-				*dest.(*string) = src
-			default:
-				return newError("invalid conversion from %T to %T (value %v)", src, dest, dest)
-			}
-		case *int:
-			switch src := src.(type) {
-			case int64:
-				*dest.(*int) = int(src)
-			case float64:
-				*dest.(*int) = int(src)
-			case string:
-				n, err := strconv.Atoi(src)
-				if err != nil {
-					return newError("invalid conversion from %T to %T (value %v)", src, dest, dest)
-				}
-				*dest.(*int) = n
-			default:
-				return newError("invalid conversion from %T to %T (value %v)", src, dest, dest)
-			}
-		case *int64:
-			switch src := src.(type) {
-			case int64:
-				*dest.(*int64) = src
-			case float64:
-				*dest.(*int64) = int64(src)
-			case string:
-				n, err := strconv.ParseInt(src, 10, 64)
-				if err != nil {
-					return newError("invalid conversion from %T to %T (value %v)", src, dest, dest)
-				}
-				*dest.(*int64) = n
-			default:
-				return newError("invalid conversion from %T to %T (value %v)", src, dest, dest)
-			}
-		case *float64:
-			switch src := src.(type) {
-			case float64:
-				*dest.(*float64) = src
-			case int64:
-				*dest.(*float64) = float64(src)
-			case string:
-				n, err := strconv.ParseFloat(src, 64)
-				if err != nil {
-					return newError("invalid conversion from %T to %T (value %v)", src, dest, dest)
-				}
-				*dest.(*float64) = n
-			default:
-				return newError("invalid conversion from %T to %T (value %v)", src, dest, dest)
-			}
-		case *time.Time:
-			t, err := toTime(src)
-			if err != nil {
-				return newError("invalid conversion from %T to %T (value %v)", src, dest, dest)
-			}
-			*dest.(*time.Time) = t
-		default:
-			return newError("unsupported destination type: %T", dest)
+	return nil
+}
+
+// Get returns the value of the column with the given name, as decoded from
+// JSON (so may be nil if the column is NULL).
+func (r *QueryRow) Get(name string) (interface{}, error) {
+	i := columnIndex(r.Columns, name)
+	if i < 0 {
+		return nil, wrapError(ErrUnknownColumn, "column "+name)
+	}
+	if i >= len(r.Values) {
+		return nil, newError(
+			"invalid row: incorrect number of types, was %d, needed %d",
+			len(r.Values), len(r.Columns),
+		)
+	}
+	return r.Values[i], nil
+}
+
+// GetString returns the value of the column with the given name converted
+// to a string using the same rules as Scan. If the column is NULL this
+// returns an empty string.
+func (r *QueryRow) GetString(name string) (string, error) {
+	var v string
+	err := r.get(name, &v)
+	return v, err
+}
+
+// GetBytes returns the value of the column with the given name converted
+// to []byte using the same rules as Scan. If the column is NULL this
+// returns nil.
+func (r *QueryRow) GetBytes(name string) ([]byte, error) {
+	var v []byte
+	err := r.get(name, &v)
+	return v, err
+}
+
+// GetBool returns the value of the column with the given name converted to
+// a bool using the same rules as Scan. If the column is NULL this returns
+// false.
+func (r *QueryRow) GetBool(name string) (bool, error) {
+	var v bool
+	err := r.get(name, &v)
+	return v, err
+}
+
+// GetInt returns the value of the column with the given name converted to
+// an int using the same rules as Scan. If the column is NULL this returns 0.
+func (r *QueryRow) GetInt(name string) (int, error) {
+	var v int
+	err := r.get(name, &v)
+	return v, err
+}
+
+// GetInt64 returns the value of the column with the given name converted to
+// an int64 using the same rules as Scan. If the column is NULL this returns
+// 0.
+func (r *QueryRow) GetInt64(name string) (int64, error) {
+	var v int64
+	err := r.get(name, &v)
+	return v, err
+}
+
+// GetFloat64 returns the value of the column with the given name converted
+// to a float64 using the same rules as Scan. If the column is NULL this
+// returns 0.
+func (r *QueryRow) GetFloat64(name string) (float64, error) {
+	var v float64
+	err := r.get(name, &v)
+	return v, err
+}
+
+// GetTime returns the value of the column with the given name converted to
+// a time.Time using the same rules as Scan. If the column is NULL this
+// returns the zero time.
+func (r *QueryRow) GetTime(name string) (time.Time, error) {
+	var v time.Time
+	err := r.get(name, &v)
+	return v, err
+}
+
+func (r *QueryRow) get(name string, dest interface{}) error {
+	src, err := r.Get(name)
+	if err != nil {
+		return err
+	}
+	if err := scanTypedValue(src, r.columnType(columnIndex(r.Columns, name)), dest); err != nil {
+		return wrapError(err, "column "+name)
+	}
+	return nil
+}
+
+// columnType returns the declared type of column i, or an empty string if
+// unknown.
+func (r *QueryRow) columnType(i int) string {
+	if i < 0 || i >= len(r.Types) {
+		return ""
+	}
+	return r.Types[i]
+}
+
+// Map returns the row as a map of column name to value, as decoded from
+// JSON.
+func (r *QueryRow) Map() map[string]interface{} {
+	m := make(map[string]interface{}, len(r.Columns))
+	for i, column := range r.Columns {
+		if i < len(r.Values) {
+			m[column] = r.Values[i]
+		} else {
+			m[column] = nil
 		}
 	}
+	return m
+}
+
+// ScanStruct scans the row into the struct pointed to by dest, matching each
+// column to the field with the same `db:"name"` tag. Fields without a tag
+// match the column with the same name (ignoring case), and fields tagged
+// `db:"-"` are skipped. Fields of embedded structs are also matched, though
+// like encoding/json, fields in outer structs take precedence over embedded
+// fields, and an error is returned if a column matches multiple fields at
+// the same depth.
+//
+// Values are converted with the same rules as Scan. By default an error is
+// returned if a column doesn't match a field, unless
+// WithIgnoreUnknownColumns(true) is set.
+func (r *QueryRow) ScanStruct(dest interface{}, opts ...ScanOption) error {
+	conf := defaultScanConfig()
+	for _, opt := range opts {
+		opt(conf)
+	}
+
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return newError("invalid destination: expected a pointer to a struct, got %T", dest)
+	}
+
+	fields, err := structFields(v.Elem().Type(), r.Columns, conf)
+	if err != nil {
+		return err
+	}
+	return r.scanFields(v.Elem(), fields)
+}
 
+// scanFields scans the row into the fields of the struct v, where fields
+// contains the index of the field for each column (or nil to skip the
+// column).
+func (r *QueryRow) scanFields(v reflect.Value, fields [][]int) error {
+	if len(r.Columns) != len(r.Values) {
+		return newError(
+			"invalid row: incorrect number of types, was %d, needed %d",
+			len(r.Values), len(r.Columns),
+		)
+	}
+
+	for i, index := range fields {
+		if index == nil {
+			continue
+		}
+		field := fieldByIndex(v, index)
+		if err := scanTypedValue(r.Values[i], r.columnType(i), field.Addr().Interface()); err != nil {
+			return wrapError(err, "column "+r.Columns[i])
+		}
+	}
 	return nil
 }
 
+// QueryResult is the result of a query. Types contains the declared type of
+// each column (such as integer, text or blob), which is empty for columns
+// without a declared type (such as expressions).
 type QueryResult struct {
 	Columns []string        `json:"columns,omitempty"`
+	Types   []string        `json:"types,omitempty"`
 	Values  [][]interface{} `json:"values,omitempty"`
 	Error   string          `json:"error,omitempty"`
-	row     int
+	// Time is the time rqlite took to process the statement, which is only
+	// set when requested with WithTimings.
+	Time time.Duration `json:"-"`
+	row  int
+}
+
+// UnmarshalJSON decodes the query result, keeping the full precision of
+// integers by decoding them as int64 rather than float64. Other numbers (and
+// any number in a column with a declared floating point type, such as real)
+// are decoded as float64.
+//
+// Results in the associative format (see WithAssociative) are converted to
+// Columns, Types and Values, with the columns ordered by name.
+func (r *QueryResult) UnmarshalJSON(b []byte) error {
+	var result struct {
+		Columns []string                 `json:"columns"`
+		Types   json.RawMessage          `json:"types"`
+		Values  [][]interface{}          `json:"values"`
+		Rows    []map[string]interface{} `json:"rows"`
+		Error   string                   `json:"error"`
+		Time    float64                  `json:"time"`
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err := dec.Decode(&result); err != nil {
+		return err
+	}
+
+	columns := result.Columns
+	var types []string
+	values := result.Values
+	if isJSONObject(result.Types) || result.Rows != nil {
+		var typesByColumn map[string]string
+		if len(result.Types) > 0 {
+			if err := json.Unmarshal(result.Types, &typesByColumn); err != nil {
+				return err
+			}
+		}
+		var firstRow map[string]interface{}
+		if len(result.Rows) > 0 {
+			firstRow = result.Rows[0]
+		}
+		columns, types = associativeColumns(typesByColumn, firstRow)
+		values = make([][]interface{}, 0, len(result.Rows))
+		for _, row := range result.Rows {
+			values = append(values, associativeValues(columns, row))
+		}
+	} else if len(result.Types) > 0 {
+		if err := json.Unmarshal(result.Types, &types); err != nil {
+			return err
+		}
+	}
+
+	for _, row := range values {
+		if err := decodeNumbers(row, types); err != nil {
+			return err
+		}
+	}
+
+	*r = QueryResult{
+		Columns: columns,
+		Types:   types,
+		Values:  values,
+		Error:   result.Error,
+		Time:    secondsToDuration(result.Time),
+		row:     0,
+	}
+	return nil
+}
+
+// Maps returns each row as a map of column name to value, as decoded from
+// JSON. See QueryRow.Map.
+//
+// This does not affect the rows returned by Next.
+func (r *QueryResult) Maps() []map[string]interface{} {
+	maps := make([]map[string]interface{}, 0, len(r.Values))
+	for _, values := range r.Values {
+		row := QueryRow{
+			Columns: r.Columns,
+			Types:   r.Types,
+			Values:  values,
+		}
+		maps = append(maps, row.Map())
+	}
+	return maps
+}
+
+func isJSONObject(b json.RawMessage) bool {
+	b = bytes.TrimSpace(b)
+	return len(b) > 0 && b[0] == '{'
+}
+
+// associativeColumns returns the columns and their types of an associative
+// result, ordered by name since the associative format does not preserve
+// the column order. The columns are taken from the types if given,
+// otherwise from the given row (which may be nil).
+func associativeColumns(types map[string]string, row map[string]interface{}) ([]string, []string) {
+	var columns []string
+	if types != nil {
+		columns = make([]string, 0, len(types))
+		for column := range types {
+			columns = append(columns, column)
+		}
+	} else {
+		columns = make([]string, 0, len(row))
+		for column := range row {
+			columns = append(columns, column)
+		}
+	}
+	sort.Strings(columns)
+
+	var columnTypes []string
+	if types != nil {
+		columnTypes = make([]string, 0, len(columns))
+		for _, column := range columns {
+			columnTypes = append(columnTypes, types[column])
+		}
+	}
+	return columns, columnTypes
+}
+
+// associativeValues returns the values of the row ordered by columns.
+func associativeValues(columns []string, row map[string]interface{}) []interface{} {
+	values := make([]interface{}, 0, len(columns))
+	for _, column := range columns {
+		values = append(values, row[column])
+	}
+	return values
+}
+
+// decodeNumbers converts each json.Number in values to an int64 or float64
+// using decodeNumber.
+func decodeNumbers(values []interface{}, types []string) error {
+	for i, v := range values {
+		n, ok := v.(json.Number)
+		if !ok {
+			continue
+		}
+		declType := ""
+		if i < len(types) {
+			declType = types[i]
+		}
+		decoded, err := decodeNumber(n, declType)
+		if err != nil {
+			return err
+		}
+		values[i] = decoded
+	}
+	return nil
+}
+
+// decodeNumber converts n to an int64 if it is an integer, otherwise a
+// float64.
+func decodeNumber(n json.Number, declType string) (interface{}, error) {
+	if !isFloatType(declType) {
+		if i, err := n.Int64(); err == nil {
+			return i, nil
+		}
+	}
+	f, err := n.Float64()
+	if err != nil {
+		return nil, wrapError(err, "invalid number")
+	}
+	return f, nil
+}
+
+// ColumnIndex returns the index of the column with the given name, or -1
+// if there is no such column.
+func (r *QueryResult) ColumnIndex(name string) int {
+	return columnIndex(r.Columns, name)
 }
 
 func (r *QueryResult) Rows() int {
@@ -116,12 +420,64 @@ func (r *QueryResult) Next() (*QueryRow, bool) {
 	}
 	row := &QueryRow{
 		Columns: r.Columns,
+		Types:   r.Types,
 		Values:  r.Values[r.row],
 	}
 	r.row++
 	return row, true
 }
 
+// ScanAll scans every row in the result and appends them to the slice
+// pointed to by dest. The slice elements may be structs or pointers to
+// structs, and each row is scanned as described in QueryRow.ScanStruct.
+//
+// This does not affect the rows returned by Next.
+func (r *QueryResult) ScanAll(dest interface{}, opts ...ScanOption) error {
+	conf := defaultScanConfig()
+	for _, opt := range opts {
+		opt(conf)
+	}
+
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Slice {
+		return newError("invalid destination: expected a pointer to a slice, got %T", dest)
+	}
+	slice := v.Elem()
+	elemType := slice.Type().Elem()
+	isPtr := elemType.Kind() == reflect.Ptr
+	structType := elemType
+	if isPtr {
+		structType = elemType.Elem()
+	}
+	if structType.Kind() != reflect.Struct {
+		return newError("invalid destination: expected a slice of structs, got %T", dest)
+	}
+
+	fields, err := structFields(structType, r.Columns, conf)
+	if err != nil {
+		return err
+	}
+
+	for i, values := range r.Values {
+		row := QueryRow{
+			Columns: r.Columns,
+			Types:   r.Types,
+			Values:  values,
+		}
+		elem := reflect.New(structType)
+		if err := row.scanFields(elem.Elem(), fields); err != nil {
+			return wrapError(err, fmt.Sprintf("row %d", i))
+		}
+		if isPtr {
+			slice = reflect.Append(slice, elem)
+		} else {
+			slice = reflect.Append(slice, elem.Elem())
+		}
+	}
+	v.Elem().Set(slice)
+	return nil
+}
+
 type QueryResults []QueryResult
 
 func (r QueryResults) GetFirstError() string {
@@ -141,6 +497,30 @@ type ExecuteResult struct {
 	LastInsertId int64  `json:"last_insert_id,omitempty"`
 	RowsAffected int64  `json:"rows_affected,omitempty"`
 	Error        string `json:"error,omitempty"`
+	// Time is the time rqlite took to process the statement, which is only
+	// set when requested with WithTimings.
+	Time time.Duration `json:"-"`
+}
+
+// UnmarshalJSON decodes the execute result, converting the time in seconds
+// to a duration.
+func (r *ExecuteResult) UnmarshalJSON(b []byte) error {
+	var result struct {
+		LastInsertId int64   `json:"last_insert_id"`
+		RowsAffected int64   `json:"rows_affected"`
+		Error        string  `json:"error"`
+		Time         float64 `json:"time"`
+	}
+	if err := json.Unmarshal(b, &result); err != nil {
+		return err
+	}
+	*r = ExecuteResult{
+		LastInsertId: result.LastInsertId,
+		RowsAffected: result.RowsAffected,
+		Error:        result.Error,
+		Time:         secondsToDuration(result.Time),
+	}
+	return nil
 }
 
 type ExecuteResults []ExecuteResult
@@ -158,6 +538,113 @@ func (r ExecuteResults) HasError() bool {
 	return r.GetFirstError() != ""
 }
 
+// QueuedWrite describes statements queued with WithQueue.
+//
+// Like Timings, QueuedWrite is written by the request it is passed to.
+type QueuedWrite struct {
+	// SequenceNumber is the sequence number rqlite assigned to the queued
+	// statements.
+	SequenceNumber int64
+}
+
+// RequestResult is the result of a statement in a request, which contains
+// either the rows of a query or the result of an execute depending on the
+// statement. If the statement failed only Error is set.
+type RequestResult struct {
+	// Query is the query result if the statement returned rows (such as a
+	// SELECT), otherwise nil.
+	Query *QueryResult
+	// Execute is the execute result if the statement did not return rows
+	// (such as an INSERT), otherwise nil.
+	Execute *ExecuteResult
+	Error   string
+	// Time is the time rqlite took to process the statement, which is only
+	// set when requested with WithTimings.
+	Time time.Duration
+}
+
+// IsQuery returns true if the result contains the rows of a query.
+func (r *RequestResult) IsQuery() bool {
+	return r.Query != nil
+}
+
+// UnmarshalJSON decodes the request result as a query result if it
+// contains columns or rows, otherwise as an execute result.
+func (r *RequestResult) UnmarshalJSON(b []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return err
+	}
+
+	var result struct {
+		Error string  `json:"error"`
+		Time  float64 `json:"time"`
+	}
+	if err := json.Unmarshal(b, &result); err != nil {
+		return err
+	}
+	*r = RequestResult{
+		Query:   nil,
+		Execute: nil,
+		Error:   result.Error,
+		Time:    secondsToDuration(result.Time),
+	}
+	if r.Error != "" {
+		return nil
+	}
+
+	_, hasColumns := fields["columns"]
+	_, hasRows := fields["rows"]
+	if hasColumns || hasRows {
+		r.Query = &QueryResult{}
+		return json.Unmarshal(b, r.Query)
+	}
+	r.Execute = &ExecuteResult{}
+	return json.Unmarshal(b, r.Execute)
+}
+
+type RequestResults []RequestResult
+
+func (r RequestResults) GetFirstError() string {
+	for _, result := range r {
+		if result.Error != "" {
+			return result.Error
+		}
+	}
+	return ""
+}
+
+func (r RequestResults) HasError() bool {
+	return r.GetFirstError() != ""
+}
+
+// Timings contains timing information for a request. See WithTimings.
+//
+// Timings is written by the request it is passed to, so each Timings must
+// only be used by a single request at a time.
+type Timings struct {
+	// Time is the time rqlite took to process the request.
+	Time time.Duration
+	// Attempts contains each attempt to send the request, including the
+	// round-trip time measured by the client. If the request succeeded the
+	// last attempt is the successful attempt. The difference between the
+	// round-trip time of the successful attempt and Time is approximately
+	// the network time.
+	//
+	// This is only set when using the default API client.
+	Attempts []Attempt
+}
+
+// start resets the timings and returns a context that records the attempts
+// of the request.
+func (t *Timings) start(ctx context.Context) context.Context {
+	t.Time = 0
+	t.Attempts = nil
+	return withAttemptRecorder(ctx, func(attempt Attempt) {
+		t.Attempts = append(t.Attempts, attempt)
+	})
+}
+
 type Nodes map[string]struct {
 	APIAddr   string  `json:"api_addr,omitempty"`
 	Addr      string  `json:"addr,omitempty"`
@@ -167,15 +654,15 @@ type Nodes map[string]struct {
 	Error     string  `json:"error,omitempty"`
 }
 
-func toTime(src interface{}) (time.Time, error) {
-	switch src := src.(type) {
-	case int64:
-		return time.Unix(src, 0), nil
-	case float64:
-		return time.Unix(int64(src), 0), nil
-	case string:
-		return time.Parse(time.RFC3339, src)
-	default:
-		return time.Time{}, newError("invalid time convertion from %T (value: %v)", src, src)
+func columnIndex(columns []string, name string) int {
+	for i, column := range columns {
+		if column == name {
+			return i
+		}
 	}
+	return -1
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
 }
@@ -1,6 +1,9 @@
 package gorqlite
 
 import (
+	"database/sql"
+	"encoding/json"
+	"errors"
 	"testing"
 	"time"
 
@@ -110,16 +113,22 @@ func TestQueryRow_ScanToString(t *testing.T) {
 	require.Equal(t, "mystring", col1)
 }
 
-func TestQueryRow_ScanSkipNillValues(t *testing.T) {
+func TestQueryRow_ScanNilValues(t *testing.T) {
 	row := QueryRow{
-		Columns: []string{"1", "2"},
-		Values:  []interface{}{int64(1), nil},
+		Columns: []string{"1", "2", "3", "4"},
+		Values:  []interface{}{int64(1), nil, nil, nil},
 	}
-	var a int
-	var b int
-	require.Nil(t, row.Scan(&a, &b))
+	// NULL columns set the destinations to the zero value, even if they
+	// contain the value of a previous row.
+	a := 0
+	b := 5
+	c := "foo"
+	d := time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC)
+	require.Nil(t, row.Scan(&a, &b, &c, &d))
 	require.Equal(t, 1, a)
 	require.Equal(t, 0, b)
+	require.Equal(t, "", c)
+	require.Equal(t, time.Time{}, d)
 }
 
 func TestQueryRow_ScanInsufficientVars(t *testing.T) {
@@ -140,3 +149,645 @@ func TestQueryRow_ScanInsufficientValues(t *testing.T) {
 	var b int
 	require.Error(t, row.Scan(&a, &b))
 }
+
+func TestQueryRow_ScanToBytes(t *testing.T) {
+	row := QueryRow{
+		Columns: []string{"string-to-bytes", "bytes-to-bytes"},
+		Values:  []interface{}{"foo", []byte("bar")},
+	}
+	var col1 []byte
+	var col2 []byte
+	require.Nil(t, row.Scan(&col1, &col2))
+	require.Equal(t, []byte("foo"), col1)
+	require.Equal(t, []byte("bar"), col2)
+}
+
+func TestQueryRow_ScanToBool(t *testing.T) {
+	row := QueryRow{
+		Columns: []string{
+			"bool-to-bool",
+			"float64-to-bool",
+			"int64-to-bool",
+			"string-to-bool",
+		},
+		Values: []interface{}{true, float64(0), int64(1), "true"},
+	}
+	var col1, col2, col3, col4 bool
+	require.Nil(t, row.Scan(&col1, &col2, &col3, &col4))
+	require.True(t, col1)
+	require.False(t, col2)
+	require.True(t, col3)
+	require.True(t, col4)
+}
+
+func TestQueryRow_ScanToSizedInts(t *testing.T) {
+	row := QueryRow{
+		Columns: []string{"int8", "int16", "int32", "uint", "uint8", "uint64"},
+		Values: []interface{}{
+			float64(-8), float64(16), "32", float64(1), int64(255), "18446744073709551615",
+		},
+	}
+	var col1 int8
+	var col2 int16
+	var col3 int32
+	var col4 uint
+	var col5 uint8
+	var col6 uint64
+	require.Nil(t, row.Scan(&col1, &col2, &col3, &col4, &col5, &col6))
+	require.Equal(t, int8(-8), col1)
+	require.Equal(t, int16(16), col2)
+	require.Equal(t, int32(32), col3)
+	require.Equal(t, uint(1), col4)
+	require.Equal(t, uint8(255), col5)
+	require.Equal(t, uint64(18446744073709551615), col6)
+}
+
+func TestQueryRow_ScanIntOverflow(t *testing.T) {
+	row := QueryRow{
+		Columns: []string{"1"},
+		Values:  []interface{}{float64(256)},
+	}
+	var a uint8
+	require.EqualError(t, row.Scan(&a), "invalid conversion from float64 to *uint8: 256 overflows")
+
+	var b int8
+	require.EqualError(t, row.Scan(&b), "invalid conversion from float64 to *int8: 256 overflows")
+}
+
+func TestQueryRow_ScanNegativeToUint(t *testing.T) {
+	row := QueryRow{
+		Columns: []string{"1"},
+		Values:  []interface{}{float64(-1)},
+	}
+	var a uint
+	require.EqualError(t, row.Scan(&a), "invalid conversion from float64 to *uint (value -1)")
+}
+
+func TestQueryRow_ScanToFloat32(t *testing.T) {
+	row := QueryRow{
+		Columns: []string{"float64-to-float32", "string-to-float32"},
+		Values:  []interface{}{float64(1.5), "2.5"},
+	}
+	var col1, col2 float32
+	require.Nil(t, row.Scan(&col1, &col2))
+	require.Equal(t, float32(1.5), col1)
+	require.Equal(t, float32(2.5), col2)
+}
+
+func TestQueryRow_ScanToInterface(t *testing.T) {
+	row := QueryRow{
+		Columns: []string{"1", "2"},
+		Values:  []interface{}{float64(1), "foo"},
+	}
+	var col1, col2 interface{}
+	require.Nil(t, row.Scan(&col1, &col2))
+	require.Equal(t, float64(1), col1)
+	require.Equal(t, "foo", col2)
+}
+
+func TestQueryRow_ScanToPointer(t *testing.T) {
+	row := QueryRow{
+		Columns: []string{"1", "2"},
+		Values:  []interface{}{"foo", nil},
+	}
+	var a *string
+	b := new(int)
+	require.Nil(t, row.Scan(&a, &b))
+	require.Equal(t, "foo", *a)
+	require.Nil(t, b)
+}
+
+func TestQueryRow_ScanToScanner(t *testing.T) {
+	row := QueryRow{
+		Columns: []string{"1", "2", "3", "4", "5", "6"},
+		Values: []interface{}{
+			"foo", nil, float64(1), nil, "2022-01-01T20:10:51Z", float64(10),
+		},
+	}
+	var col1, col2 NullString
+	var col3, col4 NullInt64
+	var col5 NullTime
+	var col6 sql.NullInt64
+	require.Nil(t, row.Scan(&col1, &col2, &col3, &col4, &col5, &col6))
+	require.Equal(t, NullString{String: "foo", Valid: true}, col1)
+	require.Equal(t, NullString{}, col2)
+	require.Equal(t, NullInt64{Int64: 1, Valid: true}, col3)
+	require.Equal(t, NullInt64{}, col4)
+	require.Equal(t, NullTime{
+		Time:  time.Date(2022, time.January, 1, 20, 10, 51, 0, time.UTC),
+		Valid: true,
+	}, col5)
+	require.Equal(t, sql.NullInt64{Int64: 10, Valid: true}, col6)
+}
+
+func TestQueryRow_ScanToScannerInvalidConversion(t *testing.T) {
+	row := QueryRow{
+		Columns: []string{"1"},
+		Values:  []interface{}{"foo"},
+	}
+	var a NullInt64
+	require.EqualError(
+		t, row.Scan(&a),
+		"failed to scan *gorqlite.NullInt64: invalid conversion from string to *int64 (value foo)",
+	)
+	require.False(t, a.Valid)
+}
+
+func TestQueryRow_ScanUnsupportedDestination(t *testing.T) {
+	row := QueryRow{
+		Columns: []string{"1"},
+		Values:  []interface{}{"foo"},
+	}
+	var a []string
+	require.EqualError(t, row.Scan(&a), "unsupported destination type: *[]string")
+}
+
+func TestQueryRow_Get(t *testing.T) {
+	row := QueryRow{
+		Columns: []string{"id", "name", "age"},
+		Values:  []interface{}{float64(1), "foo", nil},
+	}
+
+	v, err := row.Get("name")
+	require.Nil(t, err)
+	require.Equal(t, "foo", v)
+
+	v, err = row.Get("age")
+	require.Nil(t, err)
+	require.Nil(t, v)
+
+	_, err = row.Get("unknown")
+	require.True(t, errors.Is(err, ErrUnknownColumn))
+	require.EqualError(t, err, "column unknown: unknown column")
+}
+
+func TestQueryRow_GetTyped(t *testing.T) {
+	row := QueryRow{
+		Columns: []string{"id", "name", "score", "active", "created_at", "data", "age"},
+		Values: []interface{}{
+			float64(1), "foo", "1.5", int64(1), "2022-01-01T20:10:51Z", "bar", nil,
+		},
+	}
+
+	id, err := row.GetInt64("id")
+	require.Nil(t, err)
+	require.Equal(t, int64(1), id)
+
+	n, err := row.GetInt("id")
+	require.Nil(t, err)
+	require.Equal(t, 1, n)
+
+	name, err := row.GetString("name")
+	require.Nil(t, err)
+	require.Equal(t, "foo", name)
+
+	score, err := row.GetFloat64("score")
+	require.Nil(t, err)
+	require.Equal(t, 1.5, score)
+
+	active, err := row.GetBool("active")
+	require.Nil(t, err)
+	require.True(t, active)
+
+	created, err := row.GetTime("created_at")
+	require.Nil(t, err)
+	require.Equal(t, time.Date(2022, time.January, 1, 20, 10, 51, 0, time.UTC), created)
+
+	data, err := row.GetBytes("data")
+	require.Nil(t, err)
+	require.Equal(t, []byte("bar"), data)
+
+	// NULL returns the zero value.
+	age, err := row.GetInt64("age")
+	require.Nil(t, err)
+	require.Equal(t, int64(0), age)
+}
+
+func TestQueryRow_GetTypedErrors(t *testing.T) {
+	row := QueryRow{
+		Columns: []string{"name"},
+		Values:  []interface{}{"foo"},
+	}
+
+	_, err := row.GetInt64("name")
+	require.EqualError(t, err, "column name: invalid conversion from string to *int64 (value foo)")
+
+	_, err = row.GetString("unknown")
+	require.True(t, errors.Is(err, ErrUnknownColumn))
+}
+
+func TestQueryRow_Map(t *testing.T) {
+	row := QueryRow{
+		Columns: []string{"id", "name", "age"},
+		Values:  []interface{}{float64(1), "foo", nil},
+	}
+	require.Equal(t, map[string]interface{}{
+		"id":   float64(1),
+		"name": "foo",
+		"age":  nil,
+	}, row.Map())
+}
+
+func TestQueryResult_ColumnIndex(t *testing.T) {
+	result := QueryResult{
+		Columns: []string{"id", "name"},
+	}
+	require.Equal(t, 0, result.ColumnIndex("id"))
+	require.Equal(t, 1, result.ColumnIndex("name"))
+	require.Equal(t, -1, result.ColumnIndex("unknown"))
+}
+
+func TestQueryRow_ScanBlobColumn(t *testing.T) {
+	row := QueryRow{
+		Columns: []string{"1", "2", "3"},
+		Types:   []string{"blob", "BLOB", "blob"},
+		Values:  []interface{}{"Zm9v", "YmFy", nil},
+	}
+	var col1 []byte
+	var col2 string
+	var col3 []byte
+	require.Nil(t, row.Scan(&col1, &col2, &col3))
+	require.Equal(t, []byte("foo"), col1)
+	require.Equal(t, "bar", col2)
+	require.Nil(t, col3)
+}
+
+func TestQueryRow_ScanBlobColumnInvalid(t *testing.T) {
+	row := QueryRow{
+		Columns: []string{"1"},
+		Types:   []string{"blob"},
+		Values:  []interface{}{"not base64!"},
+	}
+	var b []byte
+	require.Error(t, row.Scan(&b))
+}
+
+func TestQueryRow_ScanBlobColumnIncompatible(t *testing.T) {
+	row := QueryRow{
+		Columns: []string{"1"},
+		Types:   []string{"blob"},
+		Values:  []interface{}{"MTIz"},
+	}
+	var n int
+	require.EqualError(t, row.Scan(&n), "cannot scan blob column into *int")
+}
+
+func TestQueryRow_ScanDatetimeColumn(t *testing.T) {
+	row := QueryRow{
+		Columns: []string{"1", "2", "3", "4", "5"},
+		Types:   []string{"datetime", "datetime", "date", "timestamp", "datetime"},
+		Values: []interface{}{
+			"2022-01-01 20:10:51",
+			"2022-01-01T20:10:51.5Z",
+			"2022-01-01",
+			"2022-01-01 20:10",
+			"2022-01-01 20:10:51",
+		},
+	}
+	var col1, col2, col3 time.Time
+	var col4 NullTime
+	var col5 string
+	require.Nil(t, row.Scan(&col1, &col2, &col3, &col4, &col5))
+	require.Equal(t, time.Date(2022, time.January, 1, 20, 10, 51, 0, time.UTC), col1)
+	require.Equal(t, time.Date(2022, time.January, 1, 20, 10, 51, 5e8, time.UTC), col2)
+	require.Equal(t, time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC), col3)
+	require.Equal(t, NullTime{
+		Time:  time.Date(2022, time.January, 1, 20, 10, 0, 0, time.UTC),
+		Valid: true,
+	}, col4)
+	require.Equal(t, "2022-01-01 20:10:51", col5)
+}
+
+func TestQueryRow_ScanDatetimeColumnIncompatible(t *testing.T) {
+	row := QueryRow{
+		Columns: []string{"1"},
+		Types:   []string{"datetime"},
+		Values:  []interface{}{"2022-01-01 20:10:51"},
+	}
+	var n int64
+	require.EqualError(t, row.Scan(&n), "cannot scan datetime column into *int64")
+	var p *float64
+	require.EqualError(t, row.Scan(&p), "cannot scan datetime column into **float64")
+}
+
+func TestQueryRow_ScanDatetimeColumnNumeric(t *testing.T) {
+	row := QueryRow{
+		Columns: []string{"1"},
+		Types:   []string{"datetime"},
+		Values:  []interface{}{float64(1641067851)},
+	}
+	var n int64
+	require.Nil(t, row.Scan(&n))
+	require.Equal(t, int64(1641067851), n)
+}
+
+func TestQueryRow_ScanInvalidTime(t *testing.T) {
+	row := QueryRow{
+		Columns: []string{"1"},
+		Values:  []interface{}{"foo"},
+	}
+	var v time.Time
+	require.EqualError(t, row.Scan(&v), "invalid conversion from string to *time.Time (value foo)")
+}
+
+func TestQueryResult_UnmarshalJSON(t *testing.T) {
+	var result QueryResult
+	err := json.Unmarshal([]byte(`{
+		"columns": ["id", "score", "name"],
+		"types": ["integer", "double precision", "text"],
+		"values": [[9007199254740993, 3, "foo"], [null, 1.5, null]]
+	}`), &result)
+	require.Nil(t, err)
+	require.Equal(t, QueryResult{
+		Columns: []string{"id", "score", "name"},
+		Types:   []string{"integer", "double precision", "text"},
+		Values: [][]interface{}{
+			{int64(9007199254740993), float64(3), "foo"},
+			{nil, float64(1.5), nil},
+		},
+	}, result)
+}
+
+func TestQueryResult_UnmarshalJSONError(t *testing.T) {
+	var result QueryResult
+	err := json.Unmarshal([]byte(`{"error": "no such table: foo"}`), &result)
+	require.Nil(t, err)
+	require.Equal(t, QueryResult{Error: "no such table: foo"}, result)
+}
+
+func TestQueryResult_UnmarshalJSONAssociative(t *testing.T) {
+	var result QueryResult
+	err := json.Unmarshal([]byte(`{
+		"types": {"name": "text", "id": "integer", "score": "real"},
+		"rows": [
+			{"name": "foo", "id": 9007199254740993, "score": 3},
+			{"name": null, "id": 2, "score": 1.5}
+		]
+	}`), &result)
+	require.Nil(t, err)
+	require.Equal(t, []string{"id", "name", "score"}, result.Columns)
+	require.Equal(t, []string{"integer", "text", "real"}, result.Types)
+	require.Equal(t, [][]interface{}{
+		{int64(9007199254740993), "foo", float64(3)},
+		{int64(2), nil, float64(1.5)},
+	}, result.Values)
+
+	row, ok := result.Next()
+	require.True(t, ok)
+	var v scanTestRow
+	require.Nil(t, row.ScanStruct(&v))
+	require.Equal(t, scanTestRow{
+		scanTestBase: scanTestBase{ID: 9007199254740993},
+		Name:         "foo",
+		Score:        3,
+	}, v)
+
+	row, ok = result.Next()
+	require.True(t, ok)
+	var id int64
+	var name *string
+	var score float64
+	require.Nil(t, row.Scan(&id, &name, &score))
+	require.Equal(t, int64(2), id)
+	require.Nil(t, name)
+	require.Equal(t, 1.5, score)
+
+	_, ok = result.Next()
+	require.False(t, ok)
+}
+
+func TestQueryResult_UnmarshalJSONAssociativeWithoutTypes(t *testing.T) {
+	var result QueryResult
+	err := json.Unmarshal([]byte(`{"rows": [{"b": 1, "a": "foo"}]}`), &result)
+	require.Nil(t, err)
+	require.Equal(t, []string{"a", "b"}, result.Columns)
+	require.Equal(t, [][]interface{}{{"foo", int64(1)}}, result.Values)
+}
+
+type scanTestBase struct {
+	ID int64 `db:"id"`
+}
+
+type scanTestRow struct {
+	scanTestBase
+	Name    string    `db:"name"`
+	Score   float64   `db:"score"`
+	Created time.Time `db:"created_at"`
+	Age     int
+	Ignored string `db:"-"`
+	private string
+}
+
+func TestQueryRow_ScanStruct(t *testing.T) {
+	row := QueryRow{
+		Columns: []string{"id", "name", "score", "created_at", "age"},
+		Values: []interface{}{
+			float64(1), "foo", "1.5", "2022-01-01T20:10:51Z", float64(20),
+		},
+	}
+	var v scanTestRow
+	require.Nil(t, row.ScanStruct(&v))
+
+	expected := scanTestRow{
+		scanTestBase: scanTestBase{ID: 1},
+		Name:         "foo",
+		Score:        1.5,
+		Created:      time.Date(2022, time.January, 1, 20, 10, 51, 0, time.UTC),
+		Age:          20,
+	}
+	require.Equal(t, expected, v)
+}
+
+func TestQueryRow_ScanStructNilValues(t *testing.T) {
+	row := QueryRow{
+		Columns: []string{"id", "name"},
+		Values:  []interface{}{float64(1), nil},
+	}
+	v := scanTestRow{Name: "previous"}
+	require.Nil(t, row.ScanStruct(&v))
+	require.Equal(t, int64(1), v.ID)
+	require.Equal(t, "", v.Name)
+}
+
+func TestQueryRow_ScanStructEmbeddedPointer(t *testing.T) {
+	type Base struct {
+		ID int64 `db:"id"`
+	}
+	type Row struct {
+		*Base
+		Name string `db:"name"`
+	}
+
+	row := QueryRow{
+		Columns: []string{"id", "name"},
+		Values:  []interface{}{float64(1), "foo"},
+	}
+	var v Row
+	require.Nil(t, row.ScanStruct(&v))
+	require.Equal(t, Row{Base: &Base{ID: 1}, Name: "foo"}, v)
+}
+
+func TestQueryRow_ScanStructShadowedField(t *testing.T) {
+	type Base struct {
+		ID   int64  `db:"id"`
+		Name string `db:"name"`
+	}
+	// The embedded struct comes before the outer field, which must still
+	// take precedence.
+	type Row struct {
+		Base
+		ID int64 `db:"id"`
+	}
+
+	row := QueryRow{
+		Columns: []string{"id", "name"},
+		Values:  []interface{}{float64(7), "foo"},
+	}
+	var v Row
+	require.Nil(t, row.ScanStruct(&v))
+	require.Equal(t, Row{Base: Base{ID: 0, Name: "foo"}, ID: 7}, v)
+}
+
+func TestQueryRow_ScanStructAmbiguousField(t *testing.T) {
+	type A struct {
+		ID int64 `db:"id"`
+	}
+	type B struct {
+		ID int64 `db:"id"`
+	}
+	type Row struct {
+		A
+		B
+	}
+
+	row := QueryRow{
+		Columns: []string{"id"},
+		Values:  []interface{}{float64(7)},
+	}
+	var v Row
+	require.EqualError(
+		t, row.ScanStruct(&v),
+		"ambiguous column: id matches multiple fields in gorqlite.Row",
+	)
+}
+
+func TestQueryRow_ScanStructUnknownColumn(t *testing.T) {
+	row := QueryRow{
+		Columns: []string{"id", "unknown"},
+		Values:  []interface{}{float64(1), "foo"},
+	}
+
+	var v scanTestRow
+	require.EqualError(
+		t, row.ScanStruct(&v),
+		"unknown column: unknown has no matching field in gorqlite.scanTestRow",
+	)
+
+	v = scanTestRow{}
+	require.Nil(t, row.ScanStruct(&v, WithIgnoreUnknownColumns(true)))
+	require.Equal(t, int64(1), v.ID)
+}
+
+func TestQueryRow_ScanStructIgnoredField(t *testing.T) {
+	row := QueryRow{
+		Columns: []string{"ignored"},
+		Values:  []interface{}{"foo"},
+	}
+	var v scanTestRow
+	require.Error(t, row.ScanStruct(&v))
+}
+
+func TestQueryRow_ScanStructInvalidConversion(t *testing.T) {
+	row := QueryRow{
+		Columns: []string{"id"},
+		Values:  []interface{}{"foo"},
+	}
+	var v scanTestRow
+	require.EqualError(
+		t, row.ScanStruct(&v),
+		"column id: invalid conversion from string to *int64 (value foo)",
+	)
+}
+
+func TestQueryRow_ScanStructInvalidDestination(t *testing.T) {
+	row := QueryRow{
+		Columns: []string{"id"},
+		Values:  []interface{}{float64(1)},
+	}
+	var v scanTestRow
+	require.Error(t, row.ScanStruct(v))
+	require.Error(t, row.ScanStruct((*scanTestRow)(nil)))
+	var n int
+	require.Error(t, row.ScanStruct(&n))
+}
+
+func TestQueryResult_ScanAll(t *testing.T) {
+	result := QueryResult{
+		Columns: []string{"id", "name"},
+		Values: [][]interface{}{
+			{float64(1), "foo"},
+			{float64(2), nil},
+		},
+	}
+
+	var rows []scanTestRow
+	require.Nil(t, result.ScanAll(&rows))
+	require.Equal(t, []scanTestRow{
+		{scanTestBase: scanTestBase{ID: 1}, Name: "foo"},
+		{scanTestBase: scanTestBase{ID: 2}},
+	}, rows)
+
+	var ptrRows []*scanTestRow
+	require.Nil(t, result.ScanAll(&ptrRows))
+	require.Equal(t, []*scanTestRow{
+		{scanTestBase: scanTestBase{ID: 1}, Name: "foo"},
+		{scanTestBase: scanTestBase{ID: 2}},
+	}, ptrRows)
+
+	// ScanAll does not affect Next.
+	row, ok := result.Next()
+	require.True(t, ok)
+	require.Equal(t, []interface{}{float64(1), "foo"}, row.Values)
+}
+
+func TestQueryResult_ScanAllUnknownColumn(t *testing.T) {
+	result := QueryResult{
+		Columns: []string{"id", "unknown"},
+		Values: [][]interface{}{
+			{float64(1), "foo"},
+		},
+	}
+
+	var rows []scanTestRow
+	require.Error(t, result.ScanAll(&rows))
+	require.Nil(t, result.ScanAll(&rows, WithIgnoreUnknownColumns(true)))
+	require.Equal(t, []scanTestRow{{scanTestBase: scanTestBase{ID: 1}}}, rows)
+}
+
+func TestQueryResult_ScanAllInvalidConversion(t *testing.T) {
+	result := QueryResult{
+		Columns: []string{"id"},
+		Values: [][]interface{}{
+			{float64(1)},
+			{"foo"},
+		},
+	}
+
+	var rows []scanTestRow
+	require.EqualError(
+		t, result.ScanAll(&rows),
+		"row 1: column id: invalid conversion from string to *int64 (value foo)",
+	)
+}
+
+func TestQueryResult_ScanAllInvalidDestination(t *testing.T) {
+	result := QueryResult{
+		Columns: []string{"id"},
+		Values:  [][]interface{}{{float64(1)}},
+	}
+
+	var rows []scanTestRow
+	require.Error(t, result.ScanAll(rows))
+	var ints []int
+	require.Error(t, result.ScanAll(&ints))
+}
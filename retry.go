@@ -0,0 +1,117 @@
+package gorqlite
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// RetryPolicy configures how failed requests are retried. Each retry is sent
+// to the next known node.
+//
+// Retries wait with an exponential backoff of BaseDelay * 2^retry, capped at
+// MaxDelay. The wait returns early if the request context is done.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts (including the first
+	// attempt). If 0, this defaults to 3 attempts per known node (plus the
+	// first attempt). Set to 1 to disable retries.
+	MaxAttempts int
+	// BaseDelay is the wait before the first retry.
+	BaseDelay time.Duration
+	// MaxDelay is the maximum wait between retries, or 0 for no maximum.
+	MaxDelay time.Duration
+	// Jitter is the fraction of each wait that is randomized, between 0 and
+	// 1. Such as a jitter of 0.2 waits between 80% and 100% of the backoff.
+	Jitter float64
+	// RetryableStatusCodes are the HTTP status codes that are retried.
+	RetryableStatusCodes []int
+	// RetryableError returns true if a request that failed with err (without
+	// a response) should be retried. If nil all errors are retried, except
+	// the request context being cancelled or exceeding its deadline.
+	RetryableError func(err error) bool
+}
+
+// DefaultRetryPolicy returns the retry policy used if WithRetryPolicy is not
+// set.
+//
+// Requests rejected as too large (with status 413) are not retried, since
+// every node would reject the same request. Use BatchWriter to split large
+// writes into smaller requests.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 0,
+		BaseDelay:   100 * time.Millisecond,
+		MaxDelay:    10 * time.Second,
+		Jitter:      0,
+		RetryableStatusCodes: []int{
+			http.StatusRequestTimeout,
+			http.StatusTooManyRequests,
+			http.StatusInternalServerError,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+		},
+		RetryableError: nil,
+	}
+}
+
+// maxAttempts returns the maximum number of attempts given the number of
+// known hosts.
+func (p *RetryPolicy) maxAttempts(hosts int) int {
+	if p.MaxAttempts > 0 {
+		return p.MaxAttempts
+	}
+	return hosts*3 + 1
+}
+
+func (p *RetryPolicy) retryableStatus(statusCode int) bool {
+	for _, retryableCode := range p.RetryableStatusCodes {
+		if statusCode == retryableCode {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *RetryPolicy) retryableError(err error) bool {
+	if p.RetryableError != nil {
+		return p.RetryableError(err)
+	}
+	return !errors.Is(err, context.Canceled) &&
+		!errors.Is(err, context.DeadlineExceeded)
+}
+
+// backoff returns the wait before the given retry (starting at 0).
+func (p *RetryPolicy) backoff(retry int, random func() float64) time.Duration {
+	d := p.BaseDelay
+	for i := 0; i < retry; i++ {
+		if p.MaxDelay > 0 && d >= p.MaxDelay {
+			break
+		}
+		// Avoid overflowing.
+		if d > math.MaxInt64/2 {
+			break
+		}
+		d *= 2
+	}
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+
+	if p.Jitter > 0 {
+		jitter := p.Jitter
+		if jitter > 1 {
+			jitter = 1
+		}
+		d -= time.Duration(jitter * random() * float64(d))
+	}
+	return d
+}
+
+// randomFloat64 returns a random number in [0, 1). This is safe for
+// concurrent use.
+func randomFloat64() float64 {
+	return rand.Float64() // #nosec G404 -- Only used for jitter.
+}
@@ -0,0 +1,83 @@
+package gorqlite
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryPolicy_DefaultBackoff(t *testing.T) {
+	policy := DefaultRetryPolicy()
+	random := func() float64 { return 0.5 }
+
+	require.Equal(t, 100*time.Millisecond, policy.backoff(0, random))
+	require.Equal(t, 200*time.Millisecond, policy.backoff(1, random))
+	require.Equal(t, 3200*time.Millisecond, policy.backoff(5, random))
+	// Capped at the max delay.
+	require.Equal(t, 10*time.Second, policy.backoff(7, random))
+	require.Equal(t, 10*time.Second, policy.backoff(1000, random))
+}
+
+func TestRetryPolicy_BackoffWithoutMaxDelay(t *testing.T) {
+	policy := RetryPolicy{
+		BaseDelay: time.Second,
+		MaxDelay:  0,
+	}
+	random := func() float64 { return 0.5 }
+
+	require.Equal(t, 8*time.Second, policy.backoff(3, random))
+	// Should not overflow.
+	require.True(t, policy.backoff(1000, random) > 0)
+}
+
+func TestRetryPolicy_BackoffOverflow(t *testing.T) {
+	// A base delay of 2^62 would overflow if doubled.
+	policy := RetryPolicy{
+		BaseDelay: time.Duration(1 << 62),
+		MaxDelay:  0,
+	}
+	random := func() float64 { return 0.5 }
+
+	require.Equal(t, time.Duration(1<<62), policy.backoff(1, random))
+	require.Equal(t, time.Duration(1<<62), policy.backoff(1000, random))
+}
+
+func TestRetryPolicy_BackoffWithJitter(t *testing.T) {
+	policy := RetryPolicy{
+		BaseDelay: 100 * time.Millisecond,
+		MaxDelay:  time.Second,
+		Jitter:    0.2,
+	}
+
+	require.Equal(t, 100*time.Millisecond, policy.backoff(0, func() float64 { return 0 }))
+	require.Equal(t, 90*time.Millisecond, policy.backoff(0, func() float64 { return 0.5 }))
+	require.Equal(t, 900*time.Millisecond, policy.backoff(10, func() float64 { return 0.5 }))
+
+	for i := 0; i != 100; i++ {
+		d := policy.backoff(1, randomFloat64)
+		require.True(t, d > 160*time.Millisecond && d <= 200*time.Millisecond)
+	}
+}
+
+func TestRetryPolicy_MaxAttempts(t *testing.T) {
+	policy := DefaultRetryPolicy()
+	require.Equal(t, 10, policy.maxAttempts(3))
+
+	policy.MaxAttempts = 2
+	require.Equal(t, 2, policy.maxAttempts(3))
+}
+
+func TestRetryPolicy_Retryable(t *testing.T) {
+	policy := DefaultRetryPolicy()
+	require.True(t, policy.retryableStatus(http.StatusServiceUnavailable))
+	require.False(t, policy.retryableStatus(http.StatusBadRequest))
+	// Resending the same body would be rejected again.
+	require.False(t, policy.retryableStatus(http.StatusRequestEntityTooLarge))
+	require.True(t, policy.retryableError(fmt.Errorf("network error")))
+	require.False(t, policy.retryableError(context.Canceled))
+	require.False(t, policy.retryableError(fmt.Errorf("wrapped: %w", context.DeadlineExceeded)))
+}
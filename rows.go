@@ -0,0 +1,320 @@
+package gorqlite
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// Rows is an iterator over the rows of a streamed query result. Rather than
+// decoding the whole response into memory, each row is decoded from the
+// response body as Next is called, so memory use is bounded by the size of
+// a row rather than the number of rows.
+//
+// Rows must be closed once done to release the connection:
+//
+//	rows, err := conn.QueryStream("SELECT * FROM foo")
+//	if err != nil {
+//	  return err
+//	}
+//	defer rows.Close()
+//
+//	for rows.Next() {
+//	  var id int64
+//	  var name string
+//	  if err := rows.Scan(&id, &name); err != nil {
+//	    return err
+//	  }
+//	}
+//	if err := rows.Err(); err != nil {
+//	  return err
+//	}
+//
+// Rows is not safe for concurrent use.
+type Rows struct {
+	body    io.ReadCloser
+	dec     *json.Decoder
+	columns []string
+	types   []string
+	row     *QueryRow
+	// associative is true if the result is in the associative format, where
+	// each row is an object rather than an array.
+	associative bool
+	// done is true once there are no more rows to read.
+	done   bool
+	closed bool
+	err    error
+}
+
+// newRows decodes the response up to the first row of the first result. If
+// the response or first result contains an error, the body is closed and the
+// error returned.
+func newRows(body io.ReadCloser) (*Rows, error) {
+	dec := json.NewDecoder(body)
+	dec.UseNumber()
+	r := &Rows{
+		body:        body,
+		dec:         dec,
+		columns:     nil,
+		types:       nil,
+		row:         nil,
+		associative: false,
+		done:        false,
+		closed:      false,
+		err:         nil,
+	}
+	if err := r.readHeader(); err != nil {
+		body.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+// readHeader reads the response until the values of the first result, or
+// the end of the first result if it has no values.
+func (r *Rows) readHeader() error {
+	if err := expectDelim(r.dec, '{'); err != nil {
+		return err
+	}
+	for r.dec.More() {
+		key, err := readKey(r.dec)
+		if err != nil {
+			return err
+		}
+		switch key {
+		case "results":
+			if err := expectDelim(r.dec, '['); err != nil {
+				return err
+			}
+			if !r.dec.More() {
+				return newError("query failed: expected one result")
+			}
+			return r.readResultHeader()
+		case "error":
+			var errMsg string
+			if err := r.dec.Decode(&errMsg); err != nil {
+				return wrapError(err, "query failed: invalid response")
+			}
+			if errMsg != "" {
+				return newError("query failed: %s", errMsg)
+			}
+		default:
+			if err := skipValue(r.dec); err != nil {
+				return err
+			}
+		}
+	}
+	return newError("query failed: expected one result")
+}
+
+func (r *Rows) readResultHeader() error {
+	if err := expectDelim(r.dec, '{'); err != nil {
+		return err
+	}
+	for r.dec.More() {
+		key, err := readKey(r.dec)
+		if err != nil {
+			return err
+		}
+		switch key {
+		case "columns":
+			if err := r.dec.Decode(&r.columns); err != nil {
+				return wrapError(err, "query failed: invalid response")
+			}
+		case "types":
+			var types json.RawMessage
+			if err := r.dec.Decode(&types); err != nil {
+				return wrapError(err, "query failed: invalid response")
+			}
+			if err := r.decodeTypes(types); err != nil {
+				return err
+			}
+		case "error":
+			var errMsg string
+			if err := r.dec.Decode(&errMsg); err != nil {
+				return wrapError(err, "query failed: invalid response")
+			}
+			if errMsg != "" {
+				return newError("query failed: %s", errMsg)
+			}
+		case "values":
+			if r.columns == nil {
+				return newError("query failed: invalid response: values before columns")
+			}
+			return r.readRowsStart()
+		case "rows":
+			r.associative = true
+			return r.readRowsStart()
+		default:
+			if err := skipValue(r.dec); err != nil {
+				return err
+			}
+		}
+	}
+	// The result has no values.
+	r.done = true
+	return nil
+}
+
+// readRowsStart reads the start of the rows array. If the rows are null
+// there are no rows.
+func (r *Rows) readRowsStart() error {
+	tok, err := r.dec.Token()
+	if err != nil {
+		return wrapError(err, "query failed: invalid response")
+	}
+	if tok == nil {
+		r.done = true
+		return nil
+	}
+	if d, ok := tok.(json.Delim); !ok || d != '[' {
+		return newError("query failed: invalid response: expected [, got %v", tok)
+	}
+	return nil
+}
+
+// decodeTypes decodes the column types, which in the associative format is
+// an object mapping each column to its type.
+func (r *Rows) decodeTypes(types json.RawMessage) error {
+	if !isJSONObject(types) {
+		if err := json.Unmarshal(types, &r.types); err != nil {
+			return wrapError(err, "query failed: invalid response")
+		}
+		return nil
+	}
+
+	var typesByColumn map[string]string
+	if err := json.Unmarshal(types, &typesByColumn); err != nil {
+		return wrapError(err, "query failed: invalid response")
+	}
+	r.columns, r.types = associativeColumns(typesByColumn, nil)
+	return nil
+}
+
+// Columns returns the column names. In the associative format the columns
+// are ordered by name. If the associative result does not include types,
+// the columns are only known once the first row is read.
+func (r *Rows) Columns() []string {
+	return r.columns
+}
+
+// Types returns the declared types of each column, which is empty for
+// columns without a declared type (such as expressions).
+func (r *Rows) Types() []string {
+	return r.types
+}
+
+// Next decodes the next row, which can be read with Scan, ScanStruct or Row.
+// It returns false when there are no more rows or an error occurred, in
+// which case Err returns the error.
+func (r *Rows) Next() bool {
+	r.row = nil
+	if r.done || r.closed || r.err != nil {
+		return false
+	}
+
+	if !r.dec.More() {
+		r.done = true
+		// Consume the end of the values array.
+		if err := expectDelim(r.dec, ']'); err != nil {
+			r.err = err
+		}
+		return false
+	}
+
+	var values []interface{}
+	if r.associative {
+		var row map[string]interface{}
+		if err := r.dec.Decode(&row); err != nil {
+			r.err = wrapError(err, "query failed: invalid response")
+			return false
+		}
+		if r.columns == nil {
+			r.columns, _ = associativeColumns(nil, row)
+		}
+		values = associativeValues(r.columns, row)
+	} else if err := r.dec.Decode(&values); err != nil {
+		r.err = wrapError(err, "query failed: invalid response")
+		return false
+	}
+	if err := decodeNumbers(values, r.types); err != nil {
+		r.err = wrapError(err, "query failed: invalid response")
+		return false
+	}
+
+	r.row = &QueryRow{
+		Columns: r.columns,
+		Types:   r.types,
+		Values:  values,
+	}
+	return true
+}
+
+// Row returns the current row, or nil if Next has not returned true.
+func (r *Rows) Row() *QueryRow {
+	return r.row
+}
+
+// Scan copies the columns in the current row into the values pointed to by
+// vars. See QueryRow.Scan.
+func (r *Rows) Scan(vars ...interface{}) error {
+	if r.row == nil {
+		return newError("scan called without calling next")
+	}
+	return r.row.Scan(vars...)
+}
+
+// ScanStruct scans the current row into the struct pointed to by dest. See
+// QueryRow.ScanStruct.
+func (r *Rows) ScanStruct(dest interface{}, opts ...ScanOption) error {
+	if r.row == nil {
+		return newError("scan called without calling next")
+	}
+	return r.row.ScanStruct(dest, opts...)
+}
+
+// Err returns the error, if any, that was encountered while iterating.
+func (r *Rows) Err() error {
+	return r.err
+}
+
+// Close closes the response body. It is safe to call Close multiple times.
+func (r *Rows) Close() error {
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+	r.row = nil
+	return r.body.Close()
+}
+
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return wrapError(err, "query failed: invalid response")
+	}
+	if d, ok := tok.(json.Delim); !ok || d != delim {
+		return newError("query failed: invalid response: expected %s, got %v", delim, tok)
+	}
+	return nil
+}
+
+func readKey(dec *json.Decoder) (string, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return "", wrapError(err, "query failed: invalid response")
+	}
+	key, ok := tok.(string)
+	if !ok {
+		return "", newError("query failed: invalid response: expected key, got %v", tok)
+	}
+	return key, nil
+}
+
+// skipValue skips the next value (which may be an object or array).
+func skipValue(dec *json.Decoder) error {
+	var v json.RawMessage
+	if err := dec.Decode(&v); err != nil {
+		return wrapError(err, "query failed: invalid response")
+	}
+	return nil
+}
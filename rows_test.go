@@ -0,0 +1,285 @@
+package gorqlite
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRows_Next(t *testing.T) {
+	body := `{
+    "results": [
+        {
+            "columns": ["id", "name", "score"],
+            "types": ["integer", "text", "real"],
+            "values": [
+                [9007199254740993, "foo", 2],
+                [2, null, 1.5]
+            ],
+            "time": 0.001
+        }
+    ],
+    "time": 0.002
+}`
+	rows, err := newRows(ioutil.NopCloser(strings.NewReader(body)))
+	require.Nil(t, err)
+	defer rows.Close()
+
+	require.Equal(t, []string{"id", "name", "score"}, rows.Columns())
+	require.Equal(t, []string{"integer", "text", "real"}, rows.Types())
+
+	var id int64
+	var name *string
+	var score float64
+
+	require.True(t, rows.Next())
+	require.Equal(t, []interface{}{int64(9007199254740993), "foo", float64(2)}, rows.Row().Values)
+	require.Nil(t, rows.Scan(&id, &name, &score))
+	require.Equal(t, int64(9007199254740993), id)
+	require.Equal(t, "foo", *name)
+	require.Equal(t, float64(2), score)
+
+	require.True(t, rows.Next())
+	require.Nil(t, rows.Scan(&id, &name, &score))
+	require.Equal(t, int64(2), id)
+	require.Nil(t, name)
+	require.Equal(t, 1.5, score)
+
+	require.False(t, rows.Next())
+	require.Nil(t, rows.Err())
+	require.Nil(t, rows.Row())
+	require.Error(t, rows.Scan(&id, &name, &score))
+
+	// Next continues to return false.
+	require.False(t, rows.Next())
+}
+
+func TestRows_ScanStruct(t *testing.T) {
+	body := `{"results": [{"columns": ["id", "name"], "values": [[1, "foo"]]}]}`
+	rows, err := newRows(ioutil.NopCloser(strings.NewReader(body)))
+	require.Nil(t, err)
+	defer rows.Close()
+
+	var v scanTestRow
+	require.True(t, rows.Next())
+	require.Nil(t, rows.ScanStruct(&v))
+	require.Equal(t, scanTestRow{scanTestBase: scanTestBase{ID: 1}, Name: "foo"}, v)
+}
+
+func TestRows_Associative(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{
+			"types",
+			`{"results": [{"types": {"name": "text", "id": "integer"}, "rows": [{"name": "foo", "id": 1}, {"name": "bar", "id": 2}]}]}`,
+		},
+		{
+			"no types",
+			`{"results": [{"rows": [{"name": "foo", "id": 1}, {"name": "bar", "id": 2}]}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := newRows(ioutil.NopCloser(strings.NewReader(tt.body)))
+			require.Nil(t, err)
+			defer rows.Close()
+
+			var v scanTestRow
+			require.True(t, rows.Next())
+			require.Equal(t, []string{"id", "name"}, rows.Columns())
+			require.Equal(t, []interface{}{int64(1), "foo"}, rows.Row().Values)
+			require.Nil(t, rows.ScanStruct(&v))
+			require.Equal(t, scanTestRow{scanTestBase: scanTestBase{ID: 1}, Name: "foo"}, v)
+
+			require.True(t, rows.Next())
+			require.Nil(t, rows.ScanStruct(&v))
+			require.Equal(t, scanTestRow{scanTestBase: scanTestBase{ID: 2}, Name: "bar"}, v)
+
+			require.False(t, rows.Next())
+			require.Nil(t, rows.Err())
+		})
+	}
+}
+
+func TestRows_NoValues(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"omitted", `{"results": [{"columns": ["id"], "types": ["integer"]}]}`},
+		{"null", `{"results": [{"columns": ["id"], "values": null}]}`},
+		{"empty", `{"results": [{"columns": ["id"], "values": []}]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := newRows(ioutil.NopCloser(strings.NewReader(tt.body)))
+			require.Nil(t, err)
+			defer rows.Close()
+
+			require.Equal(t, []string{"id"}, rows.Columns())
+			require.False(t, rows.Next())
+			require.Nil(t, rows.Err())
+		})
+	}
+}
+
+func TestRows_ErrorResult(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		err  string
+	}{
+		{
+			"result error",
+			`{"results": [{"error": "no such table: foo"}]}`,
+			"query failed: no such table: foo",
+		},
+		{
+			"response error",
+			`{"error": "invalid request"}`,
+			"query failed: invalid request",
+		},
+		{
+			"no results",
+			`{"results": []}`,
+			"query failed: expected one result",
+		},
+		{
+			"values before columns",
+			`{"results": [{"values": [[1]], "columns": ["id"]}]}`,
+			"query failed: invalid response: values before columns",
+		},
+		{
+			"invalid json",
+			`{"results": [{"columns": ["id"], "values": {}}]}`,
+			"query failed: invalid response: expected [, got {",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := &closeRecorder{Reader: strings.NewReader(tt.body)}
+			_, err := newRows(body)
+			require.EqualError(t, err, tt.err)
+			require.True(t, body.closed)
+		})
+	}
+}
+
+func TestRows_InvalidRow(t *testing.T) {
+	body := `{"results": [{"columns": ["id"], "values": [[1], "foo"]}]}`
+	rows, err := newRows(ioutil.NopCloser(strings.NewReader(body)))
+	require.Nil(t, err)
+	defer rows.Close()
+
+	require.True(t, rows.Next())
+	require.False(t, rows.Next())
+	require.Error(t, rows.Err())
+}
+
+func TestRows_Close(t *testing.T) {
+	body := &closeRecorder{
+		Reader: strings.NewReader(`{"results": [{"columns": ["id"], "values": [[1], [2]]}]}`),
+	}
+	rows, err := newRows(body)
+	require.Nil(t, err)
+
+	require.True(t, rows.Next())
+	require.Nil(t, rows.Close())
+	require.True(t, body.closed)
+	require.False(t, rows.Next())
+
+	// Closing again has no effect.
+	require.Nil(t, rows.Close())
+}
+
+// Tests rows are decoded as they are read, rather than reading the whole
+// response into memory.
+func TestRows_Streaming(t *testing.T) {
+	numRows := 100000
+	body := &countingReader{
+		Reader: newRowsReader(numRows),
+		n:      0,
+	}
+	rows, err := newRows(ioutil.NopCloser(body))
+	require.Nil(t, err)
+	defer rows.Close()
+
+	require.True(t, rows.Next())
+	// Only a small buffer should be read to decode the first row.
+	require.True(t, body.n < 64*1024, "read %d bytes", body.n)
+
+	count := 1
+	for rows.Next() {
+		var id int
+		var name string
+		require.Nil(t, rows.Scan(&id, &name))
+		require.Equal(t, count, id)
+		require.Equal(t, fmt.Sprintf("name-%d", count), name)
+		count++
+	}
+	require.Nil(t, rows.Err())
+	require.Equal(t, numRows, count)
+}
+
+type closeRecorder struct {
+	io.Reader
+	closed bool
+}
+
+func (r *closeRecorder) Close() error {
+	r.closed = true
+	return nil
+}
+
+type countingReader struct {
+	io.Reader
+	n int
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	r.n += n
+	return n, err
+}
+
+// newRowsReader returns a reader for a query response with the given number
+// of rows, which generates each row as it is read.
+func newRowsReader(numRows int) io.Reader {
+	readers := []io.Reader{
+		strings.NewReader(`{"results": [{"columns": ["id", "name"], "types": ["integer", "text"], "values": [`),
+	}
+	readers = append(readers, &rowGenerator{
+		row:     0,
+		numRows: numRows,
+		buf:     bytes.Buffer{},
+	})
+	readers = append(readers, strings.NewReader(`]}]}`))
+	return io.MultiReader(readers...)
+}
+
+type rowGenerator struct {
+	row     int
+	numRows int
+	buf     bytes.Buffer
+}
+
+func (g *rowGenerator) Read(p []byte) (int, error) {
+	for g.buf.Len() < len(p) && g.row < g.numRows {
+		if g.row > 0 {
+			g.buf.WriteString(",")
+		}
+		g.buf.WriteString(fmt.Sprintf(`[%d, "name-%d"]`, g.row, g.row))
+		g.row++
+	}
+	if g.buf.Len() == 0 {
+		return 0, io.EOF
+	}
+	return g.buf.Read(p)
+}
@@ -0,0 +1,439 @@
+package gorqlite
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+
+// scanTypedValue is like scanValue, but uses the declared type of the column
+// to decode src. blob columns are base64 decoded, and datetime columns can
+// only be scanned into times, strings and bytes.
+func scanTypedValue(src interface{}, declType string, dest interface{}) error {
+	switch {
+	case isBlobType(declType):
+		if s, ok := src.(string); ok {
+			b, err := base64.StdEncoding.DecodeString(s)
+			if err != nil {
+				return wrapError(err, "invalid "+declType+" value")
+			}
+			src = b
+		}
+		switch destKind(dest) {
+		case reflect.String, reflect.Slice, reflect.Interface, reflect.Struct:
+		default:
+			return newError("cannot scan %s column into %T", declType, dest)
+		}
+	case isDatetimeType(declType):
+		if _, ok := src.(string); ok {
+			switch destKind(dest) {
+			case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16,
+				reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8,
+				reflect.Uint16, reflect.Uint32, reflect.Uint64,
+				reflect.Float32, reflect.Float64:
+				return newError("cannot scan %s column into %T", declType, dest)
+			}
+		}
+	}
+	return scanValue(src, dest)
+}
+
+// destKind returns the kind of the value dest points to, following pointer
+// to pointer destinations. sql.Scanner destinations return reflect.Struct
+// since they may accept any value.
+func destKind(dest interface{}) reflect.Kind {
+	t := reflect.TypeOf(dest)
+	for t != nil && t.Kind() == reflect.Ptr {
+		if t.Implements(scannerType) {
+			return reflect.Struct
+		}
+		t = t.Elem()
+	}
+	if t == nil {
+		return reflect.Invalid
+	}
+	if t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8 {
+		return reflect.Invalid
+	}
+	return t.Kind()
+}
+
+func isBlobType(declType string) bool {
+	return strings.Contains(strings.ToLower(declType), "blob")
+}
+
+func isFloatType(declType string) bool {
+	declType = strings.ToLower(declType)
+	return strings.Contains(declType, "real") ||
+		strings.Contains(declType, "floa") ||
+		strings.Contains(declType, "doub")
+}
+
+func isDatetimeType(declType string) bool {
+	declType = strings.ToLower(declType)
+	return strings.Contains(declType, "date") || strings.Contains(declType, "timestamp")
+}
+
+// scanValue converts src to the type of dest and stores it in dest.
+//
+// If src is NULL (nil), a pointer destination (such as **string) is set to
+// nil, a sql.Scanner is scanned with nil, and any other destination is set
+// to its zero value.
+func scanValue(src interface{}, dest interface{}) error {
+	if scanner, ok := dest.(sql.Scanner); ok {
+		if err := scanner.Scan(src); err != nil {
+			return wrapError(err, "failed to scan "+typeName(dest))
+		}
+		return nil
+	}
+
+	v := reflect.ValueOf(dest)
+	if v.Kind() == reflect.Ptr && !v.IsNil() && v.Elem().Kind() == reflect.Ptr {
+		if src == nil {
+			v.Elem().Set(reflect.Zero(v.Elem().Type()))
+			return nil
+		}
+		elem := reflect.New(v.Elem().Type().Elem())
+		if err := scanValue(src, elem.Interface()); err != nil {
+			return err
+		}
+		v.Elem().Set(elem)
+		return nil
+	}
+
+	switch dest.(type) {
+	case *interface{}, *string, *[]byte, *bool,
+		*int, *int8, *int16, *int32, *int64,
+		*uint, *uint8, *uint16, *uint32, *uint64,
+		*float32, *float64, *time.Time:
+		// Set NULL to the zero value so a destination reused across rows
+		// doesn't keep the previous row's value.
+		if src == nil {
+			v.Elem().Set(reflect.Zero(v.Elem().Type()))
+			return nil
+		}
+	}
+
+	switch dest := dest.(type) {
+	case *interface{}:
+		*dest = src
+	case *string:
+		switch src := src.(type) {
+		case string:
+			*dest = src
+		case []byte:
+			*dest = string(src)
+		default:
+			return conversionError(src, dest)
+		}
+	case *[]byte:
+		switch src := src.(type) {
+		case string:
+			*dest = []byte(src)
+		case []byte:
+			*dest = append([]byte(nil), src...)
+		default:
+			return conversionError(src, dest)
+		}
+	case *bool:
+		b, ok := toBool(src)
+		if !ok {
+			return conversionError(src, dest)
+		}
+		*dest = b
+	case *int, *int8, *int16, *int32, *int64:
+		n, ok := toInt64(src)
+		if !ok {
+			return conversionError(src, dest)
+		}
+		elem := v.Elem()
+		if elem.OverflowInt(n) {
+			return newError("invalid conversion from %T to %T: %d overflows", src, dest, n)
+		}
+		elem.SetInt(n)
+	case *uint, *uint8, *uint16, *uint32, *uint64:
+		n, ok := toUint64(src)
+		if !ok {
+			return conversionError(src, dest)
+		}
+		elem := v.Elem()
+		if elem.OverflowUint(n) {
+			return newError("invalid conversion from %T to %T: %d overflows", src, dest, n)
+		}
+		elem.SetUint(n)
+	case *float32, *float64:
+		n, ok := toFloat64(src)
+		if !ok {
+			return conversionError(src, dest)
+		}
+		elem := v.Elem()
+		if elem.OverflowFloat(n) {
+			return newError("invalid conversion from %T to %T: %v overflows", src, dest, n)
+		}
+		elem.SetFloat(n)
+	case *time.Time:
+		t, err := toTime(src)
+		if err != nil {
+			return conversionError(src, dest)
+		}
+		*dest = t
+	default:
+		return newError("unsupported destination type: %T", dest)
+	}
+
+	return nil
+}
+
+func conversionError(src interface{}, dest interface{}) error {
+	return newError("invalid conversion from %T to %T (value %v)", src, dest, src)
+}
+
+func typeName(v interface{}) string {
+	return reflect.TypeOf(v).String()
+}
+
+func toBool(src interface{}) (bool, bool) {
+	switch src := src.(type) {
+	case bool:
+		return src, true
+	case int64:
+		return src != 0, true
+	case float64:
+		return src != 0, true
+	case string:
+		b, err := strconv.ParseBool(src)
+		return b, err == nil
+	default:
+		return false, false
+	}
+}
+
+// toInt64 converts src to an int64. Floats are truncated.
+func toInt64(src interface{}) (int64, bool) {
+	switch src := src.(type) {
+	case int64:
+		return src, true
+	case float64:
+		if src < math.MinInt64 || src >= math.MaxInt64 || math.IsNaN(src) {
+			return 0, false
+		}
+		return int64(src), true
+	case bool:
+		if src {
+			return 1, true
+		}
+		return 0, true
+	case string:
+		n, err := strconv.ParseInt(src, 10, 64)
+		return n, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// toUint64 converts src to a uint64. Floats are truncated and negative
+// numbers are rejected.
+func toUint64(src interface{}) (uint64, bool) {
+	switch src := src.(type) {
+	case int64:
+		if src < 0 {
+			return 0, false
+		}
+		return uint64(src), true
+	case float64:
+		if src < 0 || src >= math.MaxUint64 || math.IsNaN(src) {
+			return 0, false
+		}
+		return uint64(src), true
+	case bool:
+		if src {
+			return 1, true
+		}
+		return 0, true
+	case string:
+		n, err := strconv.ParseUint(src, 10, 64)
+		return n, err == nil
+	default:
+		return 0, false
+	}
+}
+
+func toFloat64(src interface{}) (float64, bool) {
+	switch src := src.(type) {
+	case float64:
+		return src, true
+	case int64:
+		return float64(src), true
+	case string:
+		n, err := strconv.ParseFloat(src, 64)
+		return n, err == nil
+	default:
+		return 0, false
+	}
+}
+
+func toTime(src interface{}) (time.Time, error) {
+	switch src := src.(type) {
+	case int64:
+		return time.Unix(src, 0), nil
+	case float64:
+		return time.Unix(int64(src), 0), nil
+	case string:
+		for _, layout := range timeLayouts {
+			if t, err := time.Parse(layout, src); err == nil {
+				return t, nil
+			}
+		}
+		return time.Time{}, newError("invalid time format: %s", src)
+	default:
+		return time.Time{}, newError("invalid time convertion from %T (value: %v)", src, src)
+	}
+}
+
+// timeLayouts are the supported formats when parsing a string as a time,
+// which includes RFC 3339 and the formats used by SQLite date and time
+// functions. Times without a time zone are parsed as UTC.
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// structFields returns the index of the field in struct type t matching
+// each column, or nil if the column is ignored.
+//
+// Fields are matched like encoding/json: if multiple fields match a column,
+// the shallowest field wins, so fields in outer structs take precedence over
+// embedded fields. Among fields at the same depth a tagged field wins, and if
+// there are still multiple fields the column is ambiguous.
+func structFields(t reflect.Type, columns []string, conf *scanConfig) ([][]int, error) {
+	var candidates []structField
+	collectStructFields(t, nil, &candidates)
+
+	fields := make([][]int, 0, len(columns))
+	for _, column := range columns {
+		index, ambiguous := dominantField(candidates, column)
+		if ambiguous {
+			return nil, newError("ambiguous column: %s matches multiple fields in %s", column, t)
+		}
+		if index == nil && !conf.IgnoreUnknownColumns {
+			return nil, newError("unknown column: %s has no matching field in %s", column, t)
+		}
+		fields = append(fields, index)
+	}
+	return fields, nil
+}
+
+// structField is a field that may be matched to a column.
+type structField struct {
+	// name is the db tag of the field, or the field name if untagged.
+	name   string
+	tagged bool
+	index  []int
+}
+
+// matches returns true if the field matches column. Tagged fields must match
+// exactly, whereas untagged fields match ignoring case.
+func (f structField) matches(column string) bool {
+	if f.tagged {
+		return f.name == column
+	}
+	return strings.EqualFold(f.name, column)
+}
+
+// dominantField returns the index of the field matching column, or nil if no
+// field matches. If multiple fields match at the same depth with the same
+// precedence, ambiguous is true.
+func dominantField(candidates []structField, column string) (index []int, ambiguous bool) {
+	var dominant *structField
+	for i := range candidates {
+		f := &candidates[i]
+		if !f.matches(column) {
+			continue
+		}
+		switch {
+		case dominant == nil,
+			len(f.index) < len(dominant.index),
+			len(f.index) == len(dominant.index) && f.tagged && !dominant.tagged:
+			dominant = f
+			ambiguous = false
+		case len(f.index) == len(dominant.index) && f.tagged == dominant.tagged:
+			ambiguous = true
+		}
+	}
+	if dominant == nil || ambiguous {
+		return nil, ambiguous
+	}
+	return dominant.index, false
+}
+
+func collectStructFields(t reflect.Type, parent []int, candidates *[]structField) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		index := make([]int, 0, len(parent)+1)
+		index = append(index, parent...)
+		index = append(index, i)
+
+		tag, hasTag := field.Tag.Lookup("db")
+		if tag == "-" {
+			continue
+		}
+		if field.Anonymous && !hasTag {
+			ft := field.Type
+			if ft.Kind() == reflect.Ptr {
+				// Can't allocate pointers to unexported embedded structs.
+				if field.PkgPath != "" {
+					continue
+				}
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				collectStructFields(ft, index, candidates)
+				continue
+			}
+		}
+		// Skip unexported fields.
+		if field.PkgPath != "" {
+			continue
+		}
+
+		if hasTag && tag != "" {
+			*candidates = append(*candidates, structField{
+				name:   tag,
+				tagged: true,
+				index:  index,
+			})
+			continue
+		}
+		*candidates = append(*candidates, structField{
+			name:   field.Name,
+			tagged: false,
+			index:  index,
+		})
+	}
+}
+
+// fieldByIndex returns the field with the given index, allocating any nil
+// embedded struct pointers.
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
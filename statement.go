@@ -0,0 +1,139 @@
+package gorqlite
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"math"
+	"time"
+)
+
+// Statement is an SQL statement with optional parameters.
+// See https://github.com/rqlite/rqlite/blob/cc74ab0af7c128582b7f0fd380033d43e642a121/DOC/DATA_API.md#parameterized-statements.
+//
+// Parameters can either be positional (using ? placeholders) set with Args,
+// or named (using :name placeholders) set with NamedArgs, but not both.
+//
+// Parameters can be any of:
+//   - nil
+//   - bool
+//   - signed and unsigned integers
+//   - float32 and float64
+//   - string
+//   - []byte
+//   - time.Time (encoded as an RFC 3339 string)
+//   - driver.Valuer that returns any of the above
+type Statement struct {
+	SQL       string
+	Args      []interface{}
+	NamedArgs map[string]interface{}
+}
+
+// NewStatement returns a statement with positional parameters.
+func NewStatement(sql string, args ...interface{}) Statement {
+	return Statement{
+		SQL:       sql,
+		Args:      args,
+		NamedArgs: nil,
+	}
+}
+
+// NewNamedStatement returns a statement with named parameters.
+func NewNamedStatement(sql string, args map[string]interface{}) Statement {
+	return Statement{
+		SQL:       sql,
+		Args:      nil,
+		NamedArgs: args,
+	}
+}
+
+// MarshalJSON encodes the statement in the format expected by rqlite, which
+// is just the SQL string if there are no parameters, otherwise an array
+// containing the SQL followed by the parameters.
+func (s Statement) MarshalJSON() ([]byte, error) {
+	if len(s.Args) > 0 && len(s.NamedArgs) > 0 {
+		return nil, newError("invalid statement: cannot use both positional and named parameters")
+	}
+
+	if len(s.NamedArgs) > 0 {
+		named := make(map[string]interface{}, len(s.NamedArgs))
+		for name, arg := range s.NamedArgs {
+			v, err := encodeParameter(arg)
+			if err != nil {
+				return nil, wrapError(err, "invalid parameter "+name)
+			}
+			named[name] = v
+		}
+		return json.Marshal([]interface{}{s.SQL, named})
+	}
+
+	if len(s.Args) > 0 {
+		stmt := make([]interface{}, 0, len(s.Args)+1)
+		stmt = append(stmt, s.SQL)
+		for _, arg := range s.Args {
+			v, err := encodeParameter(arg)
+			if err != nil {
+				return nil, wrapError(err, "invalid parameter")
+			}
+			stmt = append(stmt, v)
+		}
+		return json.Marshal(stmt)
+	}
+
+	return json.Marshal(s.SQL)
+}
+
+// newStatements converts each sql string to a statement without parameters.
+func newStatements(sql []string) []Statement {
+	stmts := make([]Statement, 0, len(sql))
+	for _, s := range sql {
+		stmts = append(stmts, Statement{SQL: s})
+	}
+	return stmts
+}
+
+// encodeParameter converts a parameter to a value that encodes to the JSON
+// representation expected by rqlite.
+func encodeParameter(arg interface{}) (interface{}, error) {
+	if valuer, ok := arg.(driver.Valuer); ok {
+		v, err := valuer.Value()
+		if err != nil {
+			return nil, wrapError(err, "failed to get driver value")
+		}
+		// Avoid recursing if the valuer returns itself.
+		if _, ok := v.(driver.Valuer); ok {
+			return nil, newError("unsupported parameter type: %T", arg)
+		}
+		return encodeParameter(v)
+	}
+
+	switch arg := arg.(type) {
+	case nil, bool, string, float32, float64, int, int8, int16, int32, int64,
+		uint8, uint16, uint32:
+		return arg, nil
+	case uint:
+		if uint64(arg) > math.MaxInt64 {
+			return nil, newError("unsupported parameter: %d overflows int64", arg)
+		}
+		return arg, nil
+	case uint64:
+		if arg > math.MaxInt64 {
+			return nil, newError("unsupported parameter: %d overflows int64", arg)
+		}
+		return arg, nil
+	case []byte:
+		if arg == nil {
+			return nil, nil
+		}
+		// rqlite expects blobs as an array of bytes (rather than the base64
+		// string encoding/json uses for []byte).
+		blob := make([]int, len(arg))
+		for i, b := range arg {
+			blob[i] = int(b)
+		}
+		return blob, nil
+	case time.Time:
+		return arg.Format(time.RFC3339Nano), nil
+	default:
+		return nil, newError("unsupported parameter type: %T", arg)
+	}
+}
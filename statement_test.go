@@ -0,0 +1,96 @@
+package gorqlite
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatement_MarshalWithoutParameters(t *testing.T) {
+	b, err := json.Marshal(NewStatement("SELECT * FROM foo"))
+	require.Nil(t, err)
+	require.Equal(t, `"SELECT * FROM foo"`, string(b))
+}
+
+func TestStatement_MarshalPositionalParameters(t *testing.T) {
+	stmt := NewStatement(
+		"INSERT INTO foo(name, age) VALUES(?, ?)", "fiona", 20,
+	)
+	b, err := json.Marshal(stmt)
+	require.Nil(t, err)
+	require.Equal(t, `["INSERT INTO foo(name, age) VALUES(?, ?)","fiona",20]`, string(b))
+}
+
+func TestStatement_MarshalNamedParameters(t *testing.T) {
+	stmt := NewNamedStatement(
+		"INSERT INTO foo(name, age) VALUES(:name, :age)",
+		map[string]interface{}{
+			"name": "fiona",
+			"age":  20,
+		},
+	)
+	b, err := json.Marshal(stmt)
+	require.Nil(t, err)
+	require.Equal(t, `["INSERT INTO foo(name, age) VALUES(:name, :age)",{"age":20,"name":"fiona"}]`, string(b))
+}
+
+func TestStatement_MarshalParameterTypes(t *testing.T) {
+	stmt := NewStatement(
+		"INSERT ...",
+		nil,
+		true,
+		int8(-8),
+		uint16(16),
+		int64(math.MaxInt64),
+		uint64(math.MaxInt64),
+		float32(1.5),
+		2.5,
+		"str",
+		[]byte{0x01, 0xff},
+		[]byte(nil),
+		time.Date(2022, time.January, 1, 20, 10, 51, 500, time.UTC),
+		testValuer{v: "valuer"},
+	)
+	b, err := json.Marshal(stmt)
+	require.Nil(t, err)
+	require.Equal(
+		t,
+		`["INSERT ...",null,true,-8,16,9223372036854775807,9223372036854775807,1.5,2.5,"str",[1,255],null,"2022-01-01T20:10:51.0000005Z","valuer"]`,
+		string(b),
+	)
+}
+
+func TestStatement_MarshalUnsupportedParameter(t *testing.T) {
+	_, err := json.Marshal(NewStatement("INSERT ...", struct{}{}))
+	require.Error(t, err)
+
+	_, err = json.Marshal(NewStatement("INSERT ...", uint64(math.MaxInt64)+1))
+	require.Error(t, err)
+
+	_, err = json.Marshal(NewNamedStatement(
+		"INSERT ...", map[string]interface{}{"a": struct{}{}},
+	))
+	require.Error(t, err)
+}
+
+func TestStatement_MarshalPositionalAndNamedParameters(t *testing.T) {
+	stmt := Statement{
+		SQL:       "INSERT ...",
+		Args:      []interface{}{1},
+		NamedArgs: map[string]interface{}{"a": 1},
+	}
+	_, err := json.Marshal(stmt)
+	require.Error(t, err)
+}
+
+type testValuer struct {
+	v driver.Value
+}
+
+func (v testValuer) Value() (driver.Value, error) {
+	return v.v, nil
+}
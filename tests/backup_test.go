@@ -0,0 +1,66 @@
+//go:build system
+
+package tests
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dunstall/gorqlite"
+	"github.com/dunstall/gorqlite/cluster"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackupAPIClient_BackupThenLoad(t *testing.T) {
+	formats := []gorqlite.BackupFormat{
+		gorqlite.BackupFormatBinary,
+		gorqlite.BackupFormatSQL,
+	}
+	for _, format := range formats {
+		t.Run(string(format), func(t *testing.T) {
+			require := require.New(t)
+
+			cluster, err := cluster.OpenCluster(3)
+			require.Nil(err)
+			defer cluster.Close()
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second*15)
+			defer cancel()
+
+			require.True(cluster.WaitForHealthy(ctx))
+
+			conn := gorqlite.Open(cluster.Addrs())
+
+			execResults, err := conn.Execute([]string{
+				"CREATE TABLE foo (id integer not null primary key, name text)",
+				`INSERT INTO foo(name) VALUES("bar")`,
+				`INSERT INTO foo(name) VALUES("car")`,
+			})
+			require.Nil(err)
+			require.Equal("", execResults.GetFirstError())
+
+			var backup bytes.Buffer
+			require.Nil(conn.Backup(ctx, &backup, gorqlite.WithBackupFormat(format)))
+			require.True(backup.Len() > 0)
+
+			execResult, err := conn.ExecuteOne("DROP TABLE foo")
+			require.Nil(err)
+			require.Equal("", execResult.Error)
+
+			require.Nil(conn.Load(ctx, bytes.NewReader(backup.Bytes()), format))
+
+			queryResult, err := conn.QueryOne(
+				"SELECT id, name FROM foo ORDER BY id",
+				gorqlite.WithConsistency(gorqlite.ConsistencyStrong),
+			)
+			require.Nil(err)
+			require.Equal("", queryResult.Error)
+			require.Equal([][]interface{}{
+				{int64(1), "bar"},
+				{int64(2), "car"},
+			}, queryResult.Values)
+		})
+	}
+}
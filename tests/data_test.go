@@ -5,6 +5,7 @@ package tests
 import (
 	"context"
 	"fmt"
+	"math"
 	"testing"
 	"time"
 
@@ -50,6 +51,7 @@ func TestDataAPIClient_ExecuteThenQueryResults(t *testing.T) {
 	require.Equal("", queryResult.Error)
 	require.Equal(gorqlite.QueryResult{
 		Columns: []string{"name"},
+		Types:   []string{"text"},
 		Values:  [][]interface{}{{"fiona"}},
 	}, queryResult)
 
@@ -68,6 +70,7 @@ func TestDataAPIClient_ExecuteThenQueryResults(t *testing.T) {
 	require.Equal("", queryResult.Error)
 	require.Equal(gorqlite.QueryResult{
 		Columns: []string{"name"},
+		Types:   []string{"text"},
 		Values:  [][]interface{}{{"justin"}},
 	}, queryResult)
 
@@ -86,7 +89,8 @@ func TestDataAPIClient_ExecuteThenQueryResults(t *testing.T) {
 	require.Equal("", queryResult.Error)
 	require.Equal(gorqlite.QueryResult{
 		Columns: []string{"idCount"},
-		Values:  [][]interface{}{{float64(0)}},
+		Types:   []string{""},
+		Values:  [][]interface{}{{int64(0)}},
 	}, queryResult)
 
 	// Insert multiple rows.
@@ -113,7 +117,8 @@ func TestDataAPIClient_ExecuteThenQueryResults(t *testing.T) {
 	require.Equal("", queryResult.Error)
 	require.Equal(gorqlite.QueryResult{
 		Columns: []string{"total"},
-		Values:  [][]interface{}{{float64(numRows)}},
+		Types:   []string{""},
+		Values:  [][]interface{}{{int64(numRows)}},
 	}, queryResult)
 
 	sql = []string{}
@@ -127,6 +132,7 @@ func TestDataAPIClient_ExecuteThenQueryResults(t *testing.T) {
 	for i, result := range queryResults {
 		require.Equal(gorqlite.QueryResult{
 			Columns: []string{"name"},
+			Types:   []string{"text"},
 			Values:  [][]interface{}{{fmt.Sprintf("justin-%d", i)}},
 		}, result)
 	}
@@ -216,3 +222,289 @@ func TestDataAPIClient_ExecuteInvalidCommand(t *testing.T) {
 	}
 	require.Equal(expectedResult, result)
 }
+
+func TestDataAPIClient_ParameterizedStatements(t *testing.T) {
+	require := require.New(t)
+
+	cluster, err := cluster.OpenCluster(3)
+	require.Nil(err)
+	defer cluster.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*15)
+	defer cancel()
+
+	require.True(cluster.WaitForHealthy(ctx))
+
+	conn := gorqlite.Open(cluster.Addrs())
+
+	_, err = conn.ExecuteOne(
+		"CREATE TABLE foo (id integer not null primary key, name text, age integer)",
+	)
+	require.Nil(err)
+
+	execResults, err := conn.ExecuteStatements([]gorqlite.Statement{
+		gorqlite.NewStatement(
+			"INSERT INTO foo(name, age) VALUES(?, ?)", "fiona", 20,
+		),
+		gorqlite.NewNamedStatement(
+			"INSERT INTO foo(name, age) VALUES(:name, :age)",
+			map[string]interface{}{"name": "sinead", "age": 24},
+		),
+		// Check parameters are not interpreted as SQL.
+		gorqlite.NewStatement(
+			"INSERT INTO foo(name, age) VALUES(?, ?)", `"); DROP TABLE foo; --`, nil,
+		),
+	})
+	require.Nil(err)
+	require.Equal("", execResults.GetFirstError())
+
+	queryResults, err := conn.QueryStatements([]gorqlite.Statement{
+		gorqlite.NewStatement("SELECT name, age FROM foo WHERE name=?", "fiona"),
+		gorqlite.NewNamedStatement(
+			"SELECT name, age FROM foo WHERE age=:age",
+			map[string]interface{}{"age": 24},
+		),
+		gorqlite.NewStatement("SELECT COUNT(*) FROM foo"),
+	}, gorqlite.WithConsistency("strong"))
+	require.Nil(err)
+	require.Equal("", queryResults.GetFirstError())
+	require.Equal(3, len(queryResults))
+	require.Equal([][]interface{}{{"fiona", int64(20)}}, queryResults[0].Values)
+	require.Equal([][]interface{}{{"sinead", int64(24)}}, queryResults[1].Values)
+	require.Equal([][]interface{}{{int64(3)}}, queryResults[2].Values)
+}
+
+func TestDataAPIClient_ColumnTypes(t *testing.T) {
+	require := require.New(t)
+
+	cluster, err := cluster.OpenCluster(3)
+	require.Nil(err)
+	defer cluster.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*15)
+	defer cancel()
+
+	require.True(cluster.WaitForHealthy(ctx))
+
+	conn := gorqlite.Open(cluster.Addrs())
+
+	_, err = conn.ExecuteOne(
+		"CREATE TABLE foo (id integer not null primary key, data blob, created_at datetime)",
+	)
+	require.Nil(err)
+
+	createdAt := time.Date(2022, time.January, 1, 20, 10, 51, 0, time.UTC)
+	execResults, err := conn.ExecuteStatements([]gorqlite.Statement{
+		gorqlite.NewStatement(
+			"INSERT INTO foo(data, created_at) VALUES(?, ?)", []byte("foo"), createdAt,
+		),
+	})
+	require.Nil(err)
+	require.Equal("", execResults.GetFirstError())
+
+	queryResult, err := conn.QueryOne(
+		"SELECT data, created_at FROM foo", gorqlite.WithConsistency("strong"),
+	)
+	require.Nil(err)
+	require.Equal("", queryResult.Error)
+	require.Equal([]string{"blob", "datetime"}, queryResult.Types)
+
+	row, ok := queryResult.Next()
+	require.True(ok)
+
+	var data []byte
+	var created time.Time
+	require.Nil(row.Scan(&data, &created))
+	require.Equal([]byte("foo"), data)
+	require.True(createdAt.Equal(created))
+}
+
+func TestDataAPIClient_LargeIntegers(t *testing.T) {
+	require := require.New(t)
+
+	cluster, err := cluster.OpenCluster(3)
+	require.Nil(err)
+	defer cluster.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*15)
+	defer cancel()
+
+	require.True(cluster.WaitForHealthy(ctx))
+
+	conn := gorqlite.Open(cluster.Addrs())
+
+	_, err = conn.ExecuteOne(
+		"CREATE TABLE foo (id integer not null primary key, name text)",
+	)
+	require.Nil(err)
+
+	// 9007199254740993 (2^53 + 1) can't be represented exactly as a float64.
+	ids := []int64{9007199254740993, math.MaxInt64}
+	for _, id := range ids {
+		execResult, err := conn.ExecuteStatements([]gorqlite.Statement{
+			gorqlite.NewStatement("INSERT INTO foo(id, name) VALUES(?, ?)", id, "foo"),
+		})
+		require.Nil(err)
+		require.Equal("", execResult.GetFirstError())
+		require.Equal(id, execResult[0].LastInsertId)
+	}
+
+	queryResult, err := conn.QueryOne(
+		"SELECT id FROM foo ORDER BY id", gorqlite.WithConsistency("strong"),
+	)
+	require.Nil(err)
+	require.Equal("", queryResult.Error)
+	require.Equal([][]interface{}{{ids[0]}, {ids[1]}}, queryResult.Values)
+
+	for _, expected := range ids {
+		row, ok := queryResult.Next()
+		require.True(ok)
+
+		var id int64
+		require.Nil(row.Scan(&id))
+		require.Equal(expected, id)
+	}
+}
+
+func TestDataAPIClient_Request(t *testing.T) {
+	require := require.New(t)
+
+	cluster, err := cluster.OpenCluster(3)
+	require.Nil(err)
+	defer cluster.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*15)
+	defer cancel()
+
+	require.True(cluster.WaitForHealthy(ctx))
+
+	conn := gorqlite.Open(cluster.Addrs())
+
+	results, err := conn.Request([]string{
+		"CREATE TABLE foo (id integer not null primary key, name text)",
+		`INSERT INTO foo(name) VALUES("bar")`,
+		"SELECT id, name FROM foo",
+	}, gorqlite.WithConsistency("strong"))
+	require.Nil(err)
+	require.Equal("", results.GetFirstError())
+	require.Equal(3, len(results))
+
+	require.False(results[0].IsQuery())
+	require.Equal(int64(1), results[1].Execute.LastInsertId)
+	require.Equal(int64(1), results[1].Execute.RowsAffected)
+
+	require.True(results[2].IsQuery())
+	require.Equal([]string{"id", "name"}, results[2].Query.Columns)
+	require.Equal([][]interface{}{{int64(1), "bar"}}, results[2].Query.Values)
+}
+
+func TestDataAPIClient_QueuedWrites(t *testing.T) {
+	require := require.New(t)
+
+	cluster, err := cluster.OpenCluster(3)
+	require.Nil(err)
+	defer cluster.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*15)
+	defer cancel()
+
+	require.True(cluster.WaitForHealthy(ctx))
+
+	conn := gorqlite.Open(cluster.Addrs())
+
+	_, err = conn.ExecuteOne("CREATE TABLE foo (id integer not null primary key, name text)")
+	require.Nil(err)
+
+	var queued gorqlite.QueuedWrite
+	results, err := conn.Execute([]string{
+		`INSERT INTO foo(name) VALUES("bar")`,
+		`INSERT INTO foo(name) VALUES("car")`,
+	}, gorqlite.WithQueue(&queued), gorqlite.WithQueueWait(true))
+	require.Nil(err)
+	require.Equal(0, len(results))
+	require.NotEqual(int64(0), queued.SequenceNumber)
+
+	// Since we waited for the queue to be written the rows are visible.
+	queryResult, err := conn.QueryOne(
+		"SELECT COUNT(*) FROM foo", gorqlite.WithConsistency("strong"),
+	)
+	require.Nil(err)
+	require.Equal([][]interface{}{{int64(2)}}, queryResult.Values)
+}
+
+func TestDataAPIClient_ConsistencyNoneWithFreshness(t *testing.T) {
+	require := require.New(t)
+
+	// With two nodes, once the leader stops the follower can't elect a new
+	// leader.
+	cluster, err := cluster.OpenCluster(2)
+	require.Nil(err)
+	defer cluster.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+	defer cancel()
+
+	require.True(cluster.WaitForHealthy(ctx))
+
+	conn := gorqlite.Open(cluster.Addrs())
+	_, err = conn.Request([]string{
+		"CREATE TABLE foo (id integer not null primary key, name text)",
+		`INSERT INTO foo(name) VALUES("bar")`,
+	}, gorqlite.WithConsistency(gorqlite.ConsistencyStrong))
+	require.Nil(err)
+
+	status, err := conn.StatusWithContext(ctx)
+	require.Nil(err)
+	var leaderID, followerID uint32
+	for id := range cluster.NodeAddrs() {
+		if fmt.Sprintf("%d", id) == status.Store.Leader.NodeID {
+			leaderID = id
+		} else {
+			followerID = id
+		}
+	}
+	require.NotEqual(uint32(0), leaderID)
+	require.NotEqual(uint32(0), followerID)
+
+	// Wait for the follower to apply the writes before stopping the leader.
+	conn = gorqlite.Open([]string{cluster.NodeAddrs()[followerID]})
+	for {
+		queryResult, err := conn.QueryOneWithContext(
+			ctx, "SELECT name FROM foo", gorqlite.WithConsistency(gorqlite.ConsistencyNone),
+		)
+		if err == nil && queryResult.Error == "" && len(queryResult.Values) == 1 {
+			break
+		}
+		require.Nil(ctx.Err())
+		time.Sleep(time.Millisecond * 100)
+	}
+
+	require.Nil(cluster.StopNode(leaderID))
+
+	// Wait for the follower to not have heard from the leader for longer
+	// than the freshness.
+	time.Sleep(time.Second * 2)
+
+	// With level none the follower reads from its local database.
+	queryResult, err := conn.QueryOneWithContext(
+		ctx, "SELECT name FROM foo", gorqlite.WithConsistency(gorqlite.ConsistencyNone),
+	)
+	require.Nil(err)
+	require.Equal("", queryResult.Error)
+	require.Equal([][]interface{}{{"bar"}}, queryResult.Values)
+
+	// With freshness the read is rejected as stale.
+	queryResult, err = conn.QueryOneWithContext(
+		ctx,
+		"SELECT name FROM foo",
+		gorqlite.WithConsistency(gorqlite.ConsistencyNone),
+		gorqlite.WithFreshness(time.Second),
+	)
+	require.True(err != nil || queryResult.Error != "")
+
+	// With level weak the read requires a leader.
+	queryResult, err = conn.QueryOneWithContext(
+		ctx, "SELECT name FROM foo", gorqlite.WithConsistency(gorqlite.ConsistencyWeak),
+	)
+	require.True(err != nil || queryResult.Error != "")
+}
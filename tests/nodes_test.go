@@ -4,6 +4,7 @@ package tests
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -35,3 +36,30 @@ func TestNodesAPIClient_PeerStatus(t *testing.T) {
 		}
 	}
 }
+
+func TestNodesAPIClient_RemoveNode(t *testing.T) {
+	require := require.New(t)
+
+	cluster, err := cluster.OpenCluster(3)
+	require.Nil(err)
+	defer cluster.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+	defer cancel()
+
+	require.True(cluster.WaitForHealthy(ctx))
+
+	require.Nil(cluster.RemoveNode(ctx, 3))
+	require.True(cluster.WaitForHealthy(ctx))
+
+	conn := gorqlite.Open(cluster.Addrs())
+	nodes, err := conn.NodesWithContext(ctx)
+	require.Nil(err)
+	require.Equal(2, len(nodes))
+	_, ok := nodes["3"]
+	require.False(ok)
+
+	// Removing the node again fails since it is no longer a member.
+	err = conn.RemoveNode(ctx, "3")
+	require.True(errors.Is(err, gorqlite.ErrUnknownNode))
+}